@@ -1,9 +1,11 @@
 package main
 
 import (
-	"os"
+	"strings"
 
 	"github.com/alecthomas/kingpin"
+	"github.com/sourcegraph/lsif-test/cmd/lsif-validate/internal/report"
+	"github.com/sourcegraph/lsif-test/internal/reader"
 )
 
 var app = kingpin.New(
@@ -12,7 +14,25 @@ var app = kingpin.New(
 ).Version(version)
 
 var (
-	indexFile *os.File
+	validateCommand = app.Command("validate", "Validate an LSIF index.").Default()
+	explainCommand  = app.Command("explain", "Describe a validation rule.")
+	schemaCommand   = app.Command("schema", "Print the schema of vertex and edge labels.")
+)
+
+var (
+	indexFiles        []string
+	configFile        string
+	printConfig       bool
+	outputFormat      string
+	maxErrors         int
+	maxDetailedErrors int
+	sourceRoot        string
+	baselineFile      string
+	writeBaselineFile string
+	diskBacked        bool
+	schemaFile        string
+	ruleFlags         = map[string]string{}
+	ruleID            string
 )
 
 func init() {
@@ -20,13 +40,90 @@ func init() {
 	app.VersionFlag.Short('v')
 	app.HelpFlag.Hidden()
 
-	app.Arg("index-file", "The LSIF index to validate.").Default("dump.lsif").FileVar(&indexFile)
+	validateCommand.Flag("config", "A YAML configuration file (default: "+defaultConfigFile+" if it exists).").ExistingFileVar(&configFile)
+	validateCommand.Flag("print-config", "Print the effective configuration and exit.").BoolVar(&printConfig)
+	validateCommand.Flag("format", "The output format of the validation report ("+strings.Join(report.Formats(), ", ")+").").EnumVar(&outputFormat, report.Formats()...)
+	validateCommand.Flag("max-errors", "The maximum number of errors to report (0 for no limit).").Default("-1").IntVar(&maxErrors)
+	validateCommand.Flag("max-detailed-errors", "The maximum number of errors listed individually per relationship rule before the remainder are summarized (0 for no limit).").Default("-1").IntVar(&maxDetailedErrors)
+	validateCommand.Flag("source-root", "A directory containing the indexed sources, used to check that each document exists.").ExistingDirVar(&sourceRoot)
+	validateCommand.Flag("baseline", "A baseline file of known errors that are not reported.").ExistingFileVar(&baselineFile)
+	validateCommand.Flag("write-baseline", "Write all detected errors to the given baseline file instead of reporting them.").StringVar(&writeBaselineFile)
+	validateCommand.Flag("disk-backed", "Store vertex payloads in a temporary file instead of in memory, for indexes larger than the available memory.").BoolVar(&diskBacked)
+	validateCommand.Flag("schema", "A YAML file declaring the vertex and edge labels to check, replacing the built-in schema.").ExistingFileVar(&schemaFile)
+	validateCommand.Flag("rule", "Set the severity (error, warning, off) of a rule, e.g. --rule LSIF0014=warning. May be repeated.").StringMapVar(&ruleFlags)
+	validateCommand.Flag("stdin", "Validate the index read from stdin, in place of a '-' argument.").Hidden().SetValue(&indexFilesValue{files: &indexFiles, stdin: true})
+	validateCommand.Arg("index-files", "The LSIF indexes to validate, optionally compressed with gzip or zstd ('-' for stdin, default: "+defaultIndexFile+").").SetValue(&indexFilesValue{files: &indexFiles})
+
+	explainCommand.Arg("rule", "The identifier (e.g. LSIF0004) or name (e.g. range-vertex) of the rule to describe.").Required().StringVar(&ruleID)
+
+	schemaCommand.Flag("schema", "A YAML file declaring the vertex and edge labels to check, replacing the built-in schema.").ExistingFileVar(&schemaFile)
 }
 
-func parseArgs(args []string) (err error) {
-	if _, err := app.Parse(args); err != nil {
-		return err
+// defaultIndexFile is the index validated when no index files are supplied.
+const defaultIndexFile = "dump.lsif"
+
+func parseArgs(args []string) (command string, err error) {
+	indexFiles = nil
+
+	command, err = app.Parse(rewriteStdinArgs(args))
+	if err != nil {
+		return "", err
 	}
 
+	if len(indexFiles) == 0 {
+		indexFiles = []string{defaultIndexFile}
+	}
+
+	return command, nil
+}
+
+// rewriteStdinArgs replaces each "-" argument preceding a "--" argument with the hidden --stdin
+// flag, as kingpin rejects a lone "-" as an empty short flag. Both add stdin to the index files in
+// the position of the argument.
+func rewriteStdinArgs(args []string) []string {
+	rewritten := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			return append(rewritten, args[i:]...)
+		}
+
+		if arg == reader.StdinPath {
+			arg = "--stdin"
+		}
+
+		rewritten = append(rewritten, arg)
+	}
+
+	return rewritten
+}
+
+// indexFilesValue is a kingpin value that collects the paths of the indexes to validate in the
+// order in which they are supplied. If stdin is set, the value is a repeatable boolean flag that
+// adds the path "-", which refers to stdin, each time it is supplied.
+type indexFilesValue struct {
+	files *[]string
+	stdin bool
+}
+
+func (v *indexFilesValue) Set(value string) error {
+	if v.stdin {
+		value = reader.StdinPath
+	}
+
+	*v.files = append(*v.files, value)
 	return nil
 }
+
+func (v *indexFilesValue) String() string {
+	return strings.Join(*v.files, ", ")
+}
+
+// IsCumulative marks the value as accepting multiple arguments.
+func (v *indexFilesValue) IsCumulative() bool {
+	return true
+}
+
+// IsBoolFlag marks the --stdin flag as taking no value.
+func (v *indexFilesValue) IsBoolFlag() bool {
+	return v.stdin
+}
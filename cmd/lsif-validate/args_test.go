@@ -0,0 +1,27 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArgsIndexFiles(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		{args: nil, expected: []string{"dump.lsif"}},
+		{args: []string{"a.lsif", "-", "--max-errors", "10", "b.lsif.gz"}, expected: []string{"a.lsif", "-", "b.lsif.gz"}},
+		{args: []string{"validate", "-"}, expected: []string{"-"}},
+		{args: []string{"a.lsif", "--", "-", "--stdin"}, expected: []string{"a.lsif", "-", "--stdin"}},
+	}
+
+	for _, testCase := range testCases {
+		if _, err := parseArgs(testCase.args); err != nil {
+			t.Fatalf("unexpected error parsing %v: %s", testCase.args, err)
+		}
+		if !reflect.DeepEqual(indexFiles, testCase.expected) {
+			t.Errorf("unexpected index files for %v: want %v, have %v", testCase.args, testCase.expected, indexFiles)
+		}
+	}
+}
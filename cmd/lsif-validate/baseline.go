@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+// baselineVersion is the version of the baseline file format.
+const baselineVersion = 1
+
+// baseline is the contents of a baseline file: the set of known errors that are suppressed in
+// later validations of the same project.
+type baseline struct {
+	Version int             `json:"version"`
+	Entries []baselineEntry `json:"entries"`
+}
+
+// baselineEntry describes a single known error. The rule and message are informational only;
+// errors are matched by fingerprint.
+type baselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Rule        string `json:"rule"`
+	Message     string `json:"message"`
+}
+
+// writeBaseline writes a baseline containing every error of the given reports to the given path.
+func writeBaseline(path string, reports []validation.Report) error {
+	b := baseline{Version: baselineVersion, Entries: []baselineEntry{}}
+	for _, report := range reports {
+		for _, err := range report.Errors {
+			b.Entries = append(b.Entries, baselineEntry{
+				Fingerprint: report.Fingerprint(err),
+				Rule:        err.Rule,
+				Message:     err.Message,
+			})
+		}
+	}
+
+	contents, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+// readBaseline reads the baseline file at the given path.
+func readBaseline(path string) (*baseline, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &baseline{}
+	if err := json.Unmarshal(contents, b); err != nil {
+		return nil, fmt.Errorf("malformed baseline file %s: %v", path, err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", b.Version, path)
+	}
+
+	return b, nil
+}
+
+// applyBaseline removes the errors of the given reports that match an entry of the given baseline
+// and returns the number of removed errors. Each baseline entry suppresses at most one error, so
+// additional occurrences of a known problem are still reported.
+func applyBaseline(b *baseline, reports []validation.Report) int {
+	counts := map[string]int{}
+	for _, entry := range b.Entries {
+		counts[entry.Fingerprint]++
+	}
+
+	suppressed := 0
+	for i, report := range reports {
+		var errs []*validation.Error
+		for _, err := range report.Errors {
+			if fingerprint := report.Fingerprint(err); counts[fingerprint] > 0 {
+				counts[fingerprint]--
+				continue
+			}
+
+			errs = append(errs, err)
+		}
+
+		suppressed += len(report.Errors) - len(errs)
+		reports[i].Errors = errs
+	}
+
+	return suppressed
+}
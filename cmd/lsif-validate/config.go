@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sourcegraph/lsif-test/cmd/lsif-validate/internal/report"
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultConfigFile is the configuration file read from the working directory when no
+// configuration file is explicitly supplied.
+const defaultConfigFile = ".lsif-validate.yaml"
+
+// config is the contents of an lsif-validate configuration file.
+type config struct {
+	// Format is the output format of the validation report.
+	Format string `yaml:"format,omitempty"`
+	// MaxErrors is the maximum number of errors included in the report (0 for no limit).
+	MaxErrors int `yaml:"maxErrors,omitempty"`
+	// MaxDetailedErrors is the maximum number of errors listed individually for a single
+	// relationship rule before the remainder are summarized (0 for no limit).
+	MaxDetailedErrors *int `yaml:"maxDetailedErrors,omitempty"`
+	// SourceRoot is a local directory containing the indexed sources. If set, each document
+	// must refer to a file that exists within this directory.
+	SourceRoot string `yaml:"sourceRoot,omitempty"`
+	// ReachabilityIgnore is the set of vertex labels that need not be reachable from a range
+	// or document.
+	ReachabilityIgnore []string `yaml:"reachabilityIgnore,omitempty"`
+	// Baseline is a file of known errors that are not reported.
+	Baseline string `yaml:"baseline,omitempty"`
+	// DiskBacked stores vertex payloads in a temporary file instead of in memory.
+	DiskBacked bool `yaml:"diskBacked,omitempty"`
+	// Schema is a YAML file declaring the vertex and edge labels to check. If empty, the
+	// built-in schema is checked.
+	Schema string `yaml:"schema,omitempty"`
+	// Rules is a map from rule identifiers or names to the severity of that rule.
+	Rules map[string]string `yaml:"rules,omitempty"`
+
+	// registry holds the rules and validators to check, including the schema.
+	registry *validation.Registry
+}
+
+// readConfig reads the configuration file at the given path. If no path is supplied, the
+// default configuration file is read from the working directory if it exists; otherwise an
+// empty configuration is returned. Relative paths within the file are resolved against the
+// directory containing the file.
+func readConfig(path string) (*config, error) {
+	cfg := &config{}
+	if path == "" {
+		if _, err := os.Stat(defaultConfigFile); err != nil {
+			if os.IsNotExist(err) {
+				return cfg, nil
+			}
+
+			return nil, err
+		}
+
+		path = defaultConfigFile
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(contents, cfg); err != nil {
+		return nil, fmt.Errorf("malformed config file %s: %v", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for _, p := range []*string{&cfg.SourceRoot, &cfg.Baseline, &cfg.Schema} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+
+	return cfg, nil
+}
+
+// applyFlags overrides the values of the configuration with the values of the flags that were
+// explicitly supplied on the command line.
+func (cfg *config) applyFlags() {
+	if outputFormat != "" {
+		cfg.Format = outputFormat
+	}
+	if maxErrors >= 0 {
+		cfg.MaxErrors = maxErrors
+	}
+	if maxDetailedErrors >= 0 {
+		cfg.MaxDetailedErrors = &maxDetailedErrors
+	}
+	if sourceRoot != "" {
+		cfg.SourceRoot = sourceRoot
+	}
+	if baselineFile != "" {
+		cfg.Baseline = baselineFile
+	}
+	if diskBacked {
+		cfg.DiskBacked = true
+	}
+	if schemaFile != "" {
+		cfg.Schema = schemaFile
+	}
+
+	if len(ruleFlags) > 0 && cfg.Rules == nil {
+		cfg.Rules = map[string]string{}
+	}
+	for idOrName, severity := range ruleFlags {
+		cfg.Rules[idOrName] = severity
+	}
+}
+
+// resolve validates the configuration and fills in default values. After resolution, the
+// rules map contains the severity of every known rule keyed by rule identifier.
+func (cfg *config) resolve() error {
+	if cfg.Format == "" {
+		cfg.Format = "text"
+	}
+	if _, ok := report.Reporters[cfg.Format]; !ok {
+		return fmt.Errorf("unknown format %s (expected %s)", cfg.Format, strings.Join(report.Formats(), ", "))
+	}
+
+	if cfg.MaxErrors < 0 {
+		return fmt.Errorf("maxErrors must be non-negative")
+	}
+
+	if cfg.MaxDetailedErrors == nil {
+		defaultMaxDetailedErrors := validation.DefaultMaxDetailedErrors
+		cfg.MaxDetailedErrors = &defaultMaxDetailedErrors
+	}
+	if *cfg.MaxDetailedErrors < 0 {
+		return fmt.Errorf("maxDetailedErrors must be non-negative")
+	}
+
+	if cfg.ReachabilityIgnore == nil {
+		cfg.ReachabilityIgnore = validation.DefaultReachabilityIgnoreList
+	}
+
+	rules := map[string]string{}
+	for _, rule := range validation.Rules() {
+		rules[rule.ID] = string(validation.SeverityError)
+		if rule.DefaultSeverity != "" {
+			rules[rule.ID] = string(rule.DefaultSeverity)
+		}
+	}
+
+	for idOrName, value := range cfg.Rules {
+		rule, ok := validation.LookupRule(idOrName)
+		if !ok {
+			return fmt.Errorf("unknown rule %s", idOrName)
+		}
+
+		severity, err := validation.ParseSeverity(value)
+		if err != nil {
+			return fmt.Errorf("rule %s: %v", idOrName, err)
+		}
+
+		rules[rule.ID] = string(severity)
+	}
+
+	cfg.Rules = rules
+
+	cfg.registry = validation.NewRegistry()
+	if cfg.Schema != "" {
+		contents, err := ioutil.ReadFile(cfg.Schema)
+		if err != nil {
+			return err
+		}
+
+		schema, err := validation.ParseSchema(contents)
+		if err != nil {
+			return fmt.Errorf("malformed schema file %s: %v", cfg.Schema, err)
+		}
+		if err := cfg.registry.SetSchema(schema); err != nil {
+			return fmt.Errorf("invalid schema file %s: %v", cfg.Schema, err)
+		}
+	}
+
+	return nil
+}
+
+// severities returns a map from rule identifiers to the severity of that rule. This method
+// must be called on a resolved configuration.
+func (cfg *config) severities() map[string]validation.Severity {
+	severities := map[string]validation.Severity{}
+	for id, severity := range cfg.Rules {
+		severities[id] = validation.Severity(severity)
+	}
+
+	return severities
+}
+
+// print writes the configuration to stdout as YAML.
+func (cfg *config) print() error {
+	contents, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(contents)
+	return err
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadConfigResolvesRelativePaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lsif-validate-config-")
+	if err != nil {
+		t.Fatalf("unexpected error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	absoluteSchema := filepath.Join(dir, "schema.yaml")
+	contents := "sourceRoot: ../src\nbaseline: baseline.json\nschema: " + absoluteSchema + "\n"
+
+	path := filepath.Join(dir, "ci", defaultConfigFile)
+	if err := os.Mkdir(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("unexpected error creating directory: %s", err)
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("unexpected error writing config file: %s", err)
+	}
+
+	cfg, err := readConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading config file: %s", err)
+	}
+
+	if expected := filepath.Join(dir, "src"); cfg.SourceRoot != expected {
+		t.Errorf("unexpected source root: want %s, have %s", expected, cfg.SourceRoot)
+	}
+	if expected := filepath.Join(dir, "ci", "baseline.json"); cfg.Baseline != expected {
+		t.Errorf("unexpected baseline: want %s, have %s", expected, cfg.Baseline)
+	}
+	if cfg.Schema != absoluteSchema {
+		t.Errorf("unexpected schema: want %s, have %s", absoluteSchema, cfg.Schema)
+	}
+}
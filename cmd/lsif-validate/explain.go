@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+func explain(ruleID string) error {
+	rule, ok := validation.LookupRule(ruleID)
+	if !ok {
+		return fmt.Errorf("unknown rule %s", ruleID)
+	}
+
+	fmt.Printf("%s (%s)\n\n", rule.ID, rule.Name)
+	fmt.Printf("%s\n\n", rule.Description)
+	fmt.Printf("Why: %s\n\n", rule.Rationale)
+	if rule.DefaultSeverity != "" {
+		fmt.Printf("Default severity: %s\n\n", rule.DefaultSeverity)
+	}
+	fmt.Printf("Failing example:\n\n%s\n\n", indent(rule.FailingExample))
+	fmt.Printf("Passing example:\n\n%s\n", indent(rule.PassingExample))
+	return nil
+}
+
+// indent prefixes each line of the given text with a tab.
+func indent(text string) string {
+	return "\t" + strings.Replace(text, "\n", "\n\t", -1)
+}
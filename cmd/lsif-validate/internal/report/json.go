@@ -0,0 +1,136 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+// jsonReport is the top-level object emitted by the JSON output format for a single index file.
+type jsonReport struct {
+	File    string      `json:"file,omitempty"`
+	Errors  []jsonError `json:"errors"`
+	Summary jsonSummary `json:"summary"`
+}
+
+// jsonMultiReport is the top-level object emitted by the JSON output format for multiple index
+// files. The summary is the sum of the summaries of each index file.
+type jsonMultiReport struct {
+	Indexes []jsonReport `json:"indexes"`
+	Summary jsonSummary  `json:"summary"`
+}
+
+// jsonError is the JSON representation of a single validation error.
+type jsonError struct {
+	Rule     string            `json:"rule"`
+	Severity string            `json:"severity"`
+	Message  string            `json:"message"`
+	Lines    []jsonLineContext `json:"lines"`
+}
+
+// jsonLineContext is the JSON representation of a line relevant to a validation error.
+type jsonLineContext struct {
+	Index   int             `json:"index"`
+	ID      int             `json:"id"`
+	Type    string          `json:"type"`
+	Label   string          `json:"label"`
+	Payload json.RawMessage `json:"payload"`
+	Raw     string          `json:"raw,omitempty"`
+}
+
+// jsonSummary holds the element, error, and warning counts of the validated index, as well as
+// the number of errors omitted from the report.
+type jsonSummary struct {
+	Vertices uint64 `json:"vertices"`
+	Edges    uint64 `json:"edges"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Omitted  int    `json:"omitted"`
+}
+
+// writeJSONReport writes the errors and summary of the given results as a single JSON object.
+// A single result is written as its report; multiple results are written as a list of reports,
+// each naming its index file, along with a combined summary.
+func writeJSONReport(w io.Writer, results []Result) error {
+	reports := make([]jsonReport, 0, len(results))
+	summary := jsonSummary{}
+	for _, result := range results {
+		report, err := makeJSONReport(result.Report)
+		if err != nil {
+			return err
+		}
+		report.File = result.Filename
+		reports = append(reports, report)
+
+		summary.Vertices += report.Summary.Vertices
+		summary.Edges += report.Summary.Edges
+		summary.Errors += report.Summary.Errors
+		summary.Warnings += report.Summary.Warnings
+		summary.Omitted += report.Summary.Omitted
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if len(reports) == 1 {
+		reports[0].File = ""
+		return encoder.Encode(reports[0])
+	}
+
+	return encoder.Encode(jsonMultiReport{Indexes: reports, Summary: summary})
+}
+
+// makeJSONReport converts the errors and summary of the given validation report into its JSON representation.
+func makeJSONReport(report validation.Report) (jsonReport, error) {
+	reported := report.ReportedErrors()
+	errs := make([]jsonError, 0, len(reported))
+	for _, err := range reported {
+		lines := make([]jsonLineContext, 0, len(err.RelevantLines))
+		for _, lineContext := range err.RelevantLines {
+			jsonLine, err := makeJSONLineContext(lineContext)
+			if err != nil {
+				return jsonReport{}, err
+			}
+
+			lines = append(lines, jsonLine)
+		}
+
+		errs = append(errs, jsonError{
+			Rule:     err.Rule,
+			Severity: string(err.Severity),
+			Message:  err.Message,
+			Lines:    lines,
+		})
+	}
+
+	numFailures := report.NumFailures()
+
+	return jsonReport{
+		Errors: errs,
+		Summary: jsonSummary{
+			Vertices: report.NumVertices,
+			Edges:    report.NumEdges,
+			Errors:   numFailures,
+			Warnings: len(report.Errors) - numFailures,
+			Omitted:  len(report.Errors) - len(reported),
+		},
+	}, nil
+}
+
+// makeJSONLineContext converts the given line context into its JSON representation.
+func makeJSONLineContext(lineContext validation.LineContext) (jsonLineContext, error) {
+	payload, err := json.Marshal(lineContext.Element.Payload)
+	if err != nil {
+		return jsonLineContext{}, err
+	}
+
+	return jsonLineContext{
+		Index:   lineContext.Index,
+		ID:      lineContext.Element.ID,
+		Type:    lineContext.Element.Type,
+		Label:   lineContext.Element.Label,
+		Payload: payload,
+		Raw:     lineContext.Raw,
+	}, nil
+}
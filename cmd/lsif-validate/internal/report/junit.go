@@ -0,0 +1,114 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// writeJUnitReport writes the errors of the given results as a JUnit XML document. Each known
+// rule becomes a test case of a test suite named after the index file, and a test case fails
+// if its rule raised at least one error, whether or not that error is among the reported errors.
+// Only reported errors are listed in detail. Warnings are attached to the output of their test
+// case without failing it.
+func writeJUnitReport(w io.Writer, results []Result) error {
+	suites := make([]junitTestSuite, 0, len(results))
+	for _, result := range results {
+		suites = append(suites, makeJUnitTestSuite(result.Filename, result.Report))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: suites}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// makeJUnitTestSuite converts the errors of the given validation report into a JUnit test suite.
+func makeJUnitTestSuite(filename string, report validation.Report) junitTestSuite {
+	groups := groupByRule(report.Errors)
+
+	reported := map[*validation.Error]struct{}{}
+	for _, err := range report.ReportedErrors() {
+		reported[err] = struct{}{}
+	}
+
+	suite := junitTestSuite{Name: filename}
+	for _, id := range ruleIDs(report) {
+		name := id
+		if rule, ok := validation.LookupRule(id); ok {
+			name = fmt.Sprintf("%s %s", id, rule.Name)
+		}
+
+		testCase := junitTestCase{Name: name, ClassName: "lsif-validate"}
+
+		numFailures := 0
+		var failures, warnings []string
+		for _, err := range groups[id] {
+			_, ok := reported[err]
+
+			if err.Severity == validation.SeverityWarning {
+				if ok {
+					warnings = append(warnings, err.Error())
+				}
+			} else {
+				numFailures++
+				if ok {
+					failures = append(failures, err.Error())
+				}
+			}
+		}
+
+		if numFailures > 0 {
+			if omitted := numFailures - len(failures); omitted > 0 {
+				failures = append(failures, fmt.Sprintf("... and %d more errors", omitted))
+			}
+
+			testCase.Failure = &junitFailure{
+				Message:  fmt.Sprintf("%d errors", numFailures),
+				Contents: strings.Join(failures, "\n\n"),
+			}
+			suite.Failures++
+		}
+		testCase.SystemOut = strings.Join(warnings, "\n\n")
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+	}
+
+	return suite
+}
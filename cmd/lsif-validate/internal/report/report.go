@@ -0,0 +1,67 @@
+package report
+
+import (
+	"io"
+	"sort"
+
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+// Result is the completed validation of a single index file.
+type Result struct {
+	Filename string
+	Report   validation.Report
+}
+
+// Reporter writes the errors of the given completed validations to w.
+type Reporter func(w io.Writer, results []Result) error
+
+// Reporters is a map from output format names to that format's reporter.
+var Reporters = map[string]Reporter{
+	"text":  writeTextReport,
+	"json":  writeJSONReport,
+	"sarif": writeSARIFReport,
+	"junit": writeJUnitReport,
+}
+
+// Formats returns the sorted names of all registered output formats.
+func Formats() []string {
+	formats := make([]string, 0, len(Reporters))
+	for format := range Reporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// groupByRule returns a map from rule identifiers to the given errors raised by that rule. Errors
+// are kept in the order in which they were raised.
+func groupByRule(errs []*validation.Error) map[string][]*validation.Error {
+	groups := map[string][]*validation.Error{}
+	for _, err := range errs {
+		groups[err.Rule] = append(groups[err.Rule], err)
+	}
+
+	return groups
+}
+
+// ruleIDs returns the identifier of every known rule followed by the identifiers of any rules
+// attached to the errors of the given report which are not otherwise known.
+func ruleIDs(report validation.Report) []string {
+	ids := validation.RuleIDs()
+
+	known := map[string]struct{}{}
+	for _, id := range ids {
+		known[id] = struct{}{}
+	}
+
+	for _, err := range report.Errors {
+		if _, ok := known[err.Rule]; !ok {
+			known[err.Rule] = struct{}{}
+			ids = append(ids, err.Rule)
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/lsif-test/internal/testindex"
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+func TestReportsAreDeterministic(t *testing.T) {
+	index := testindex.Invalid()
+
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			var expected []byte
+			for i := 0; i < 10; i++ {
+				report, err := validation.Validate(bytes.NewReader(index), validation.Options{MaxDetailedErrors: 25})
+				if err != nil {
+					t.Fatalf("unexpected error validating index: %s", err)
+				}
+
+				var buf bytes.Buffer
+				if err := Reporters[format](&buf, []Result{{Filename: "dump.lsif", Report: report}}); err != nil {
+					t.Fatalf("unexpected error writing report: %s", err)
+				}
+
+				if i == 0 {
+					if len(report.Errors) == 0 {
+						t.Fatalf("expected validation errors")
+					}
+
+					expected = buf.Bytes()
+				} else if !bytes.Equal(buf.Bytes(), expected) {
+					t.Fatalf("report differs between runs:\n%s\n\nvs\n\n%s", expected, buf.Bytes())
+				}
+			}
+		})
+	}
+}
+
+func TestJUnitReportFailsRulesPastErrorLimit(t *testing.T) {
+	index := `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":2,"character":5},"end":{"line":1,"character":0}}
+{"id":4,"type":"vertex","label":"range","start":{"line":3,"character":0},"end":{"line":3,"character":4}}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[3]}
+`
+
+	report, err := validation.Validate(strings.NewReader(index), validation.Options{MaxErrors: 1})
+	if err != nil {
+		t.Fatalf("unexpected error validating index: %s", err)
+	}
+	if len(report.ReportedErrors()) != 1 || len(report.Errors) < 2 {
+		t.Fatalf("expected errors past the error limit, have %d of %d", len(report.ReportedErrors()), len(report.Errors))
+	}
+
+	suite := makeJUnitTestSuite("dump.lsif", report)
+
+	failed := map[string]bool{}
+	for _, testCase := range suite.TestCases {
+		failed[strings.Fields(testCase.Name)[0]] = testCase.Failure != nil
+	}
+	for _, err := range report.Errors {
+		if !failed[err.Rule] {
+			t.Errorf("expected test case of %s to fail", err.Rule)
+		}
+	}
+	if suite.Failures != len(groupByRule(report.Errors)) {
+		t.Errorf("unexpected number of failures: want %d, have %d", len(groupByRule(report.Errors)), suite.Failures)
+	}
+}
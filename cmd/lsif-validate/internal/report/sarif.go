@@ -0,0 +1,140 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	Name             string        `json:"name,omitempty"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+	FullDescription  *sarifMessage `json:"fullDescription,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// writeSARIFReport writes the errors of the given results as a SARIF 2.1.0 log containing one run
+// per index file. Each known rule is listed in the tool's rule table, and each error becomes a
+// result whose locations are the relevant lines of the index file.
+func writeSARIFReport(w io.Writer, results []Result) error {
+	runs := make([]sarifRun, 0, len(results))
+	for _, result := range results {
+		runs = append(runs, makeSARIFRun(result.Filename, result.Report))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    runs,
+	})
+}
+
+// makeSARIFRun converts the errors of the given validation report into a SARIF run.
+func makeSARIFRun(filename string, report validation.Report) sarifRun {
+	ids := ruleIDs(report)
+	rules := make([]sarifRule, 0, len(ids))
+	ruleIndexes := map[string]int{}
+	for i, id := range ids {
+		sr := sarifRule{ID: id}
+		if rule, ok := validation.LookupRule(id); ok {
+			sr.Name = rule.Name
+			sr.ShortDescription = &sarifMessage{Text: rule.Description}
+			sr.FullDescription = &sarifMessage{Text: rule.Rationale}
+		}
+
+		rules = append(rules, sr)
+		ruleIndexes[id] = i
+	}
+
+	reported := report.ReportedErrors()
+	results := make([]sarifResult, 0, len(reported))
+	for _, err := range reported {
+		locations := make([]sarifLocation, 0, len(err.RelevantLines))
+		for _, lineContext := range err.RelevantLines {
+			locations = append(locations, sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filename},
+					Region:           sarifRegion{StartLine: lineContext.Index},
+				},
+			})
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    err.Rule,
+			RuleIndex: ruleIndexes[err.Rule],
+			Level:     string(err.Severity),
+			Message:   sarifMessage{Text: err.Message},
+			Locations: locations,
+		})
+	}
+
+	return sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "lsif-validate",
+				InformationURI: "https://github.com/sourcegraph/lsif-test",
+				Rules:          rules,
+			},
+		},
+		Results: results,
+	}
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+// writeTextReport writes each reported error of the given results as a numbered, human-readable
+// entry, followed by the number of errors omitted from the report. If there are multiple results,
+// the errors of each index file are preceded by its name and followed by a combined summary.
+func writeTextReport(w io.Writer, results []Result) error {
+	if len(results) == 1 {
+		return writeTextErrors(w, results[0].Report)
+	}
+
+	for _, result := range results {
+		if _, err := fmt.Fprintf(w, "==> %s <==\n", result.Filename); err != nil {
+			return err
+		}
+		if err := writeTextErrors(w, result.Report); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	for _, result := range results {
+		numFailures := result.Report.NumFailures()
+		numWarnings := len(result.Report.Errors) - numFailures
+
+		if _, err := fmt.Fprintf(w, "%s: %d errors, %d warnings\n", result.Filename, numFailures, numWarnings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTextErrors writes each reported error of the given validation report as a numbered entry, followed
+// by the number of errors omitted from the report.
+func writeTextErrors(w io.Writer, report validation.Report) error {
+	reported := report.ReportedErrors()
+	for i, err := range reported {
+		if _, err := fmt.Fprintf(w, "%d) %s\n", i+1, err); err != nil {
+			return err
+		}
+	}
+
+	if omitted := len(report.Errors) - len(reported); omitted > 0 {
+		if _, err := fmt.Fprintf(w, "... and %d more errors\n", omitted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -15,10 +15,32 @@ func main() {
 }
 
 func mainErr() error {
-	if err := parseArgs(os.Args[1:]); err != nil {
+	command, err := parseArgs(os.Args[1:])
+	if err != nil {
 		return err
 	}
-	defer indexFile.Close()
 
-	return validate(indexFile)
+	if command == explainCommand.FullCommand() {
+		return explain(ruleID)
+	}
+
+	cfg, err := readConfig(configFile)
+	if err != nil {
+		return err
+	}
+
+	cfg.applyFlags()
+	if err := cfg.resolve(); err != nil {
+		return err
+	}
+
+	if printConfig {
+		return cfg.print()
+	}
+
+	if command == schemaCommand.FullCommand() {
+		return printSchema(cfg)
+	}
+
+	return validate(os.Stdout, indexFiles, cfg)
 }
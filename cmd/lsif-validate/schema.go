@@ -0,0 +1,22 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+// printSchema writes the schema checked with the given configuration to stdout.
+func printSchema(cfg *config) error {
+	contents := []byte(validation.DefaultSchemaYAML)
+	if cfg.Schema != "" {
+		var err error
+		if contents, err = ioutil.ReadFile(cfg.Schema); err != nil {
+			return err
+		}
+	}
+
+	_, err := os.Stdout.Write(contents)
+	return err
+}
@@ -3,62 +3,158 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
-	"sync/atomic"
 	"time"
 
 	"github.com/efritz/pentimento"
-	"github.com/sourcegraph/lsif-test/cmd/lsif-validate/internal/validation"
+	"github.com/sourcegraph/lsif-test/cmd/lsif-validate/internal/report"
+	"github.com/sourcegraph/lsif-test/internal/reader"
+	"github.com/sourcegraph/lsif-test/pkg/validation"
 )
 
 var updateInterval = time.Second / 4
 var ticker = pentimento.NewAnimatedString([]string{"⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏", "⠋", "⠙", "⠹"}, updateInterval)
 
-func validate(indexFile *os.File) error {
-	ctx := validation.NewValidationContext()
-	validator := &validation.Validator{Context: ctx}
-	errs := make(chan error, 1)
+// validate validates the given index files and writes a combined report to the given writer. An
+// error is returned if any index has errors with error severity.
+func validate(w io.Writer, indexFiles []string, cfg *config) error {
+	var knownErrors *baseline
+	if cfg.Baseline != "" && writeBaselineFile == "" {
+		b, err := readBaseline(cfg.Baseline)
+		if err != nil {
+			return err
+		}
 
-	go func() {
-		defer close(errs)
+		knownErrors = b
+	}
 
-		if err := validator.Validate(indexFile); err != nil {
-			errs <- err
+	reports := make([]validation.Report, 0, len(indexFiles))
+	for _, indexFile := range indexFiles {
+		indexReport, err := validateIndex(indexFile, cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %v", indexFile, err)
 		}
-	}()
 
-	if err := printProgress(ctx, validator, errs); err != nil {
+		reports = append(reports, indexReport)
+	}
+
+	if writeBaselineFile != "" {
+		if err := writeBaseline(writeBaselineFile, reports); err != nil {
+			return err
+		}
+
+		numErrors := 0
+		for _, indexReport := range reports {
+			numErrors += len(indexReport.Errors)
+		}
+
+		fmt.Fprintf(os.Stderr, "Wrote %d errors to baseline %s\n", numErrors, writeBaselineFile)
+		return nil
+	}
+
+	if knownErrors != nil {
+		if suppressed := applyBaseline(knownErrors, reports); suppressed > 0 {
+			fmt.Fprintf(os.Stderr, "Suppressed %d errors present in baseline %s\n", suppressed, cfg.Baseline)
+		}
+	}
+
+	results := make([]report.Result, 0, len(reports))
+	for i, indexFile := range indexFiles {
+		filename := indexFile
+		if indexFile == reader.StdinPath {
+			filename = "stdin"
+		}
+
+		results = append(results, report.Result{Filename: filename, Report: reports[i]})
+	}
+
+	if err := report.Reporters[cfg.Format](w, results); err != nil {
 		return err
 	}
 
-	for i, err := range ctx.Errors {
-		fmt.Printf("%d) %s\n", i+1, err)
+	numFailures, numFailedIndexes := 0, 0
+	for _, indexReport := range reports {
+		if n := indexReport.NumFailures(); n > 0 {
+			numFailures += n
+			numFailedIndexes++
+		}
 	}
 
-	if len(ctx.Errors) > 0 {
-		return errors.New(fmt.Sprintf("Detected %d errors", len(ctx.Errors)))
+	if numFailures > 0 {
+		if len(reports) > 1 {
+			return errors.New(fmt.Sprintf("Detected %d errors in %d of %d indexes", numFailures, numFailedIndexes, len(reports)))
+		}
+
+		return errors.New(fmt.Sprintf("Detected %d errors", numFailures))
 	}
 
 	return nil
 }
 
-func printProgress(ctx *validation.ValidationContext, validator *validation.Validator, errs <-chan error) error {
+// validateIndex reads and validates the index at the given path.
+func validateIndex(indexFile string, cfg *config) (validation.Report, error) {
+	r, err := reader.Open(indexFile)
+	if err != nil {
+		return validation.Report{}, err
+	}
+	defer r.Close()
+
+	maxDetailedErrors := *cfg.MaxDetailedErrors
+	if maxDetailedErrors == 0 {
+		// A limit of zero disables summarization in the configuration file
+		maxDetailedErrors = -1
+	}
+
+	progress := &validation.Progress{}
+	opts := validation.Options{
+		Severities:             cfg.severities(),
+		MaxErrors:              cfg.MaxErrors,
+		MaxDetailedErrors:      maxDetailedErrors,
+		SourceRoot:             cfg.SourceRoot,
+		ReachabilityIgnoreList: cfg.ReachabilityIgnore,
+		DiskBacked:             cfg.DiskBacked,
+		Registry:               cfg.registry,
+		Progress:               progress,
+	}
+
+	var report validation.Report
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+
+		var err error
+		if report, err = validation.Validate(r, opts); err != nil {
+			errs <- err
+		}
+	}()
+
+	if cfg.Format == "text" {
+		err = printProgress(progress, errs)
+	} else {
+		// Do not interleave progress output with a machine-readable report
+		err = <-errs
+	}
+
+	return report, err
+}
+
+func printProgress(progress *validation.Progress, errs <-chan error) error {
 	return pentimento.PrintProgress(func(printer *pentimento.Printer) error {
 		defer func() {
 			_ = printer.Reset()
 		}()
 
 		for {
-			ctx.ErrorsLock.RLock()
-			numErrors := len(ctx.Errors)
-			ctx.ErrorsLock.RUnlock()
+			numVertices, numEdges, numErrors := progress.Counts()
 
 			content := pentimento.NewContent()
 			content.AddLine(
 				"%s %d vertices, %d edges, %d errors",
 				ticker,
-				atomic.LoadUint64(&ctx.NumVertices),
-				atomic.LoadUint64(&ctx.NumEdges),
+				numVertices,
+				numEdges,
 				numErrors,
 			)
 			printer.WriteContent(content)
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const validIndex = `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":0},"end":{"line":1,"character":4}}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}
+`
+
+// invalidIndex has a range with illegal extents.
+const invalidIndex = `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":2,"character":5},"end":{"line":1,"character":0}}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}
+`
+
+func TestValidateMultipleIndexes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lsif-validate-indexes-")
+	if err != nil {
+		t.Fatalf("unexpected error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	paths := []string{filepath.Join(dir, "valid.lsif"), filepath.Join(dir, "invalid.lsif")}
+	for i, contents := range []string{validIndex, invalidIndex} {
+		if err := ioutil.WriteFile(paths[i], []byte(contents), 0644); err != nil {
+			t.Fatalf("unexpected error writing index: %s", err)
+		}
+	}
+
+	cfg := &config{Format: "json"}
+	if err := cfg.resolve(); err != nil {
+		t.Fatalf("unexpected error resolving config: %s", err)
+	}
+
+	var buf bytes.Buffer
+	err = validate(&buf, paths, cfg)
+	if err == nil || err.Error() != "Detected 1 errors in 1 of 2 indexes" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var output struct {
+		Indexes []struct {
+			File    string `json:"file"`
+			Summary struct {
+				Vertices int `json:"vertices"`
+				Errors   int `json:"errors"`
+			} `json:"summary"`
+		} `json:"indexes"`
+		Summary struct {
+			Vertices int `json:"vertices"`
+			Errors   int `json:"errors"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("unexpected error decoding report: %s\n%s", err, buf.String())
+	}
+
+	if len(output.Indexes) != 2 || output.Indexes[0].File != paths[0] || output.Indexes[1].File != paths[1] {
+		t.Fatalf("unexpected indexes in report:\n%s", buf.String())
+	}
+	if output.Indexes[0].Summary.Errors != 0 || output.Indexes[1].Summary.Errors != 1 {
+		t.Errorf("unexpected error counts: want 0 and 1, have %d and %d", output.Indexes[0].Summary.Errors, output.Indexes[1].Summary.Errors)
+	}
+	if output.Summary.Vertices != 6 || output.Summary.Errors != 1 {
+		t.Errorf("unexpected combined summary: want 6 vertices and 1 error, have %d and %d", output.Summary.Vertices, output.Summary.Errors)
+	}
+}
@@ -1,9 +1,8 @@
 package main
 
 import (
-	"os"
-
 	"github.com/alecthomas/kingpin"
+	"github.com/sourcegraph/lsif-test/internal/reader"
 )
 
 var app = kingpin.New(
@@ -12,7 +11,7 @@ var app = kingpin.New(
 ).Version(version)
 
 var (
-	indexFile     *os.File
+	indexFile     string
 	fromID        int
 	subgraphDepth int
 )
@@ -25,13 +24,35 @@ func init() {
 	app.Flag("from-id", "The edge/vertex ID to visualize a subgraph from. Must be used in combination with '-depth'.").Default("2").IntVar(&fromID)
 	app.Flag("depth", "Depth limit of the subgraph to be output").Default("-1").IntVar(&subgraphDepth)
 
-	app.Arg("index-file", "The LSIF index to visualize.").Default("dump.lsif").FileVar(&indexFile)
+	app.Arg("index-file", "The LSIF index to visualize, optionally compressed with gzip or zstd ('-' for stdin).").Default("dump.lsif").StringVar(&indexFile)
 }
 
 func parseArgs(args []string) (err error) {
-	if _, err := app.Parse(args); err != nil {
+	if _, err := app.Parse(protectStdinArgs(args)); err != nil {
 		return err
 	}
 
+	if indexFile == stdinArg {
+		indexFile = reader.StdinPath
+	}
+
 	return nil
 }
+
+// stdinArg stands in for the "-" argument while parsing, as kingpin rejects a lone "-" as an
+// empty short flag.
+const stdinArg = "\x00stdin"
+
+// protectStdinArgs replaces each "-" argument with stdinArg.
+func protectStdinArgs(args []string) []string {
+	protected := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == reader.StdinPath {
+			arg = stdinArg
+		}
+
+		protected = append(protected, arg)
+	}
+
+	return protected
+}
@@ -5,7 +5,7 @@ import (
 )
 
 type VisualizationContext struct {
-	Stasher *reader.Stasher
+	Stasher reader.Stasher
 }
 
 func NewVisualizationContext() *VisualizationContext {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"regexp"
 	"strings"
 
@@ -19,24 +20,25 @@ type Visualizer struct {
 }
 
 func (v *Visualizer) Visualize(indexFile io.Reader, fromID, subgraphDepth int) error {
-	if err := reader2.Read(indexFile, v.Context.Stasher, nil, nil); err != nil {
+	if err := reader2.Read(indexFile, v.Context.Stasher, nil, nil, v.errorMapper); err != nil {
 		return err
 	}
 
-	forwardEdges := buildForwardGraph(v.Context.Stasher)
-	backwardEdges := invertEdges(forwardEdges)
+	graph := reader2.NewGraph(v.Context.Stasher)
 	vertices := map[int]struct{}{}
-	getReachableVerticesAtDepth(fromID, forwardEdges, backwardEdges, subgraphDepth, vertices)
+	getReachableVerticesAtDepth(fromID, graph, subgraphDepth, vertices)
 
 	fmt.Printf("digraph G {\n")
 
 	var b bytes.Buffer
 	enc := json.NewEncoder(&b)
-	_ = v.Context.Stasher.Vertices(func(lineContext reader2.LineContext) bool {
-		if _, ok := vertices[lineContext.Element.ID]; !ok {
+	_ = v.Context.Stasher.VertexLabels(func(id int, label string) bool {
+		if _, ok := vertices[id]; !ok {
 			return true
 		}
 
+		lineContext, _ := v.Context.Stasher.Vertex(id)
+
 		if lineContext.Element.Payload != nil {
 			if err := enc.Encode(lineContext.Element.Payload); err != nil {
 				fmt.Println(":bomb emoji:")
@@ -55,14 +57,14 @@ func (v *Visualizer) Visualize(indexFile io.Reader, fromID, subgraphDepth int) e
 		return true
 	})
 
-	_ = v.Context.Stasher.Edges(func(lineContext reader2.LineContext, edge reader.Edge) bool {
+	_ = v.Context.Stasher.EdgeLabels(func(id int, label string, edge reader.Edge) bool {
 		if _, ok := vertices[edge.OutV]; !ok {
 			return true
 		}
 
-		return forEachInV(edge, func(inV int) bool {
+		return reader2.ForEachInV(edge, func(inV int) bool {
 			if _, ok := vertices[inV]; ok {
-				fmt.Printf("\tv%d -> v%d [label=\"(%d) %s\"];\n", edge.OutV, inV, lineContext.Element.ID, lineContext.Element.Label)
+				fmt.Printf("\tv%d -> v%d [label=\"(%d) %s\"];\n", edge.OutV, inV, id, label)
 			}
 
 			return true
@@ -73,17 +75,21 @@ func (v *Visualizer) Visualize(indexFile io.Reader, fromID, subgraphDepth int) e
 	return nil
 }
 
-func getReachableVerticesAtDepth(from int, forwardEdges, backwardEdges map[int][]int, depth int, vertices map[int]struct{}) {
+func (v *Visualizer) errorMapper(err *reader2.ValidationError) {
+	fmt.Fprintf(os.Stderr, "skipping %s\n", err)
+}
+
+func getReachableVerticesAtDepth(from int, graph *reader2.Graph, depth int, vertices map[int]struct{}) {
 	if _, ok := vertices[from]; ok || depth == 0 {
 		return
 	}
 
 	vertices[from] = struct{}{}
 
-	for _, v := range forwardEdges[from] {
-		getReachableVerticesAtDepth(v, forwardEdges, backwardEdges, depth-1, vertices)
+	for _, v := range graph.OutNeighbors(from) {
+		getReachableVerticesAtDepth(v, graph, depth-1, vertices)
 	}
-	for _, v := range backwardEdges[from] {
-		getReachableVerticesAtDepth(v, forwardEdges, backwardEdges, depth-1, vertices)
+	for _, v := range graph.InNeighbors(from) {
+		getReachableVerticesAtDepth(v, graph, depth-1, vertices)
 	}
 }
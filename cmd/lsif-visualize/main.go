@@ -18,7 +18,6 @@ func mainErr() error {
 	if err := parseArgs(os.Args[1:]); err != nil {
 		return err
 	}
-	defer indexFile.Close()
 
 	return visualize(indexFile, fromID, subgraphDepth)
 }
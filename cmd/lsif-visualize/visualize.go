@@ -1,13 +1,18 @@
 package main
 
 import (
-	"os"
-
 	"github.com/sourcegraph/lsif-test/cmd/lsif-visualize/internal/visualization"
+	"github.com/sourcegraph/lsif-test/internal/reader"
 )
 
-func visualize(indexFile *os.File, fromID, subgraphDepth int) error {
+func visualize(indexFile string, fromID, subgraphDepth int) error {
+	r, err := reader.Open(indexFile)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
 	ctx := visualization.NewVisualizationContext()
 	visualizer := &visualization.Visualizer{Context: ctx}
-	return visualizer.Visualize(indexFile, fromID, subgraphDepth)
+	return visualizer.Visualize(r, fromID, subgraphDepth)
 }
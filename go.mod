@@ -7,7 +7,10 @@ require (
 	github.com/alecthomas/template v0.0.0-20190718012654-fb15b899a751 // indirect
 	github.com/alecthomas/units v0.0.0-20190924025748-f65c72e2690d // indirect
 	github.com/efritz/pentimento v0.0.0-20190429011147-ade47d831101
+	github.com/json-iterator/go v1.1.10
+	github.com/klauspost/compress v1.11.13
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.1 // indirect
 	github.com/sourcegraph/lsif-protocol v1.0.0
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,77 @@
+package reader
+
+// denseIDSlack is the number of identifiers beyond twice the number of registered elements that
+// may be stored in the dense portion of an idIndex. Indexers generally assign small sequential
+// identifiers, which are stored densely; any others fall back to a map.
+const denseIDSlack = 1 << 16
+
+// idIndex is a mapping from element identifiers to slots.
+type idIndex struct {
+	dense  []int32 // dense[id] is one more than the slot of id, or zero if id is not registered
+	sparse map[int]int32
+	count  int
+}
+
+func newIDIndex() idIndex {
+	return idIndex{sparse: map[int]int32{}}
+}
+
+// get returns the slot of the given identifier.
+func (x *idIndex) get(id int) (int32, bool) {
+	if id >= 0 && id < len(x.dense) && x.dense[id] != 0 {
+		return x.dense[id] - 1, true
+	}
+
+	slot, ok := x.sparse[id]
+	return slot, ok
+}
+
+// set registers the slot of the given identifier.
+func (x *idIndex) set(id int, slot int32) {
+	x.count++
+
+	if id < 0 || id >= 2*x.count+denseIDSlack {
+		x.sparse[id] = slot
+		return
+	}
+
+	if id >= len(x.dense) {
+		size := 2 * len(x.dense)
+		if size <= id {
+			size = id + 1
+		}
+
+		dense := make([]int32, size)
+		copy(dense, x.dense)
+		x.dense = dense
+	}
+
+	x.dense[id] = slot + 1
+}
+
+// labelTable interns element labels as small integers.
+type labelTable struct {
+	ids    map[string]uint32
+	labels []string
+}
+
+func newLabelTable() labelTable {
+	return labelTable{ids: map[string]uint32{}}
+}
+
+// intern returns the integer representing the given label.
+func (t *labelTable) intern(label string) uint32 {
+	if id, ok := t.ids[label]; ok {
+		return id
+	}
+
+	id := uint32(len(t.labels))
+	t.ids[label] = id
+	t.labels = append(t.labels, label)
+	return id
+}
+
+// get returns the label represented by the given integer.
+func (t *labelTable) get(id uint32) string {
+	return t.labels[id]
+}
@@ -2,8 +2,17 @@ package reader
 
 import reader "github.com/sourcegraph/lsif-protocol/reader"
 
-// LineContext holds a line index and the element parsed from that line.
+// LineContext holds a line index and the element parsed from that line. If the line could not
+// be parsed, the element is empty and Raw holds the text of the line.
+//
+// Properties holds the names of the properties of the element as they occur on the line. It is
+// populated only for the line contexts passed to the element mappers of Read. ItemProperty holds
+// the value of the property field of an item edge (e.g. "definitions" or "references"), which is
+// not part of the edge payload. It is also populated for the edges returned by a Stasher.
 type LineContext struct {
-	Index   int
-	Element reader.Element
+	Index        int
+	Element      reader.Element
+	Raw          string
+	Properties   []string
+	ItemProperty string
 }
@@ -0,0 +1,217 @@
+package reader
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+)
+
+// NewDiskStasher creates a new empty Stasher that stores vertex payloads in a temporary file in
+// the given directory (or the default temporary directory if empty). Only the identifiers, labels,
+// and line numbers of vertices are held in memory, along with every edge (whose payload is the
+// adjacency of the graph). The file is removed when the Stasher is closed. The elements returned
+// from a disk-backed Stasher are identical to those returned from an in-memory Stasher.
+//
+// If the stash file cannot be written or read, the affected vertices are returned without a
+// payload and the error is returned from Close.
+func NewDiskStasher(dir string) (Stasher, error) {
+	file, err := ioutil.TempFile(dir, "lsif-stash-")
+	if err != nil {
+		return nil, err
+	}
+
+	return newColumnStasher(&payloadFile{
+		file:   file,
+		writer: bufio.NewWriter(file),
+	}), nil
+}
+
+// payloadFile is an append-only file of serialized vertex payloads.
+type payloadFile struct {
+	file      *os.File
+	writer    *bufio.Writer
+	offset    int64 // the number of bytes written to writer
+	flushed   int64 // the number of bytes written to file
+	locations []payloadLocation
+	err       error
+	mu        sync.Mutex
+}
+
+// payloadLocation is the location of a serialized payload within a payload file.
+type payloadLocation struct {
+	offset int64
+	length int32
+}
+
+// write appends the serialized payload of the given vertex to the file and returns a reference
+// to it. This method returns false if the payload of the vertex cannot be stored on disk.
+func (f *payloadFile) write(element reader.Element) (int32, bool) {
+	if _, ok := payloadDecoders[element.Label]; !ok {
+		return 0, false
+	}
+
+	data, err := unmarshaller.Marshal(storedPayload(element.Payload))
+	if err != nil {
+		f.setErr(err)
+		return 0, false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.writer.Write(data); err != nil {
+		if f.err == nil {
+			f.err = err
+		}
+
+		return 0, false
+	}
+
+	f.locations = append(f.locations, payloadLocation{offset: f.offset, length: int32(len(data))})
+	f.offset += int64(len(data))
+	return int32(len(f.locations) - 1), true
+}
+
+// read reads and decodes the payload of a vertex with the given label from the file.
+func (f *payloadFile) read(ref int32, label string) interface{} {
+	location := f.locations[ref]
+	if err := f.flush(location.offset + int64(location.length)); err != nil {
+		f.setErr(err)
+		return nil
+	}
+
+	data := make([]byte, location.length)
+	if _, err := f.file.ReadAt(data, location.offset); err != nil {
+		f.setErr(err)
+		return nil
+	}
+
+	payload, err := payloadDecoders[label](data)
+	if err != nil {
+		f.setErr(err)
+		return nil
+	}
+
+	return payload
+}
+
+// flush ensures that the first n bytes written to the file can be read back.
+func (f *payloadFile) flush(n int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if n <= f.flushed {
+		return nil
+	}
+
+	if err := f.writer.Flush(); err != nil {
+		return err
+	}
+
+	f.flushed = f.offset
+	return nil
+}
+
+// setErr records the first error that occurs while accessing the file.
+func (f *payloadFile) setErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+// close closes and removes the file. This method returns the first error that occurred while
+// accessing the file, if any.
+func (f *payloadFile) close() error {
+	closeErr := f.file.Close()
+	removeErr := os.Remove(f.file.Name())
+
+	for _, err := range []error{f.err, closeErr, removeErr} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// payloadDecoders is a map from vertex labels to a function that decodes the serialized form of
+// that vertex's payload. There is an entry for every label with an entry in vertexUnmarshalers,
+// and for $event vertices.
+var payloadDecoders = map[string]func(data []byte) (interface{}, error){
+	"metaData": func(data []byte) (interface{}, error) {
+		var payload reader.MetaData
+		err := unmarshaller.Unmarshal(data, &payload)
+		return payload, err
+	},
+	"project": func(data []byte) (interface{}, error) {
+		var payload Project
+		err := unmarshaller.Unmarshal(data, &payload)
+		return payload, err
+	},
+	"document": decodeStringPayload,
+	"range": func(data []byte) (interface{}, error) {
+		var payload reader.Range
+		err := unmarshaller.Unmarshal(data, &payload)
+		return payload, err
+	},
+	"hoverResult": func(data []byte) (interface{}, error) {
+		var payload storedHoverResult
+		err := unmarshaller.Unmarshal(data, &payload)
+		return HoverResult(payload), err
+	},
+	"moniker": func(data []byte) (interface{}, error) {
+		var payload reader.Moniker
+		err := unmarshaller.Unmarshal(data, &payload)
+		return payload, err
+	},
+	"packageInformation": func(data []byte) (interface{}, error) {
+		var payload storedPackageInformation
+		err := unmarshaller.Unmarshal(data, &payload)
+		return PackageInformation(payload), err
+	},
+	"$event": func(data []byte) (interface{}, error) {
+		var payload storedEvent
+		err := unmarshaller.Unmarshal(data, &payload)
+		return Event(payload), err
+	},
+	"diagnosticResult": func(data []byte) (interface{}, error) {
+		var payload []reader.Diagnostic
+		err := unmarshaller.Unmarshal(data, &payload)
+		return payload, err
+	},
+}
+
+// storedHoverResult, storedPackageInformation, and storedEvent have the fields of HoverResult,
+// PackageInformation, and Event, but not their JSON encodings, which omit the fields that extend
+// the payloads of the lsif-protocol reader or that refer to element identifiers.
+type (
+	storedHoverResult        HoverResult
+	storedPackageInformation PackageInformation
+	storedEvent              Event
+)
+
+// storedPayload returns the value that is serialized to store the given payload on disk.
+func storedPayload(payload interface{}) interface{} {
+	switch p := payload.(type) {
+	case HoverResult:
+		return storedHoverResult(p)
+	case PackageInformation:
+		return storedPackageInformation(p)
+	case Event:
+		return storedEvent(p)
+	}
+
+	return payload
+}
+
+func decodeStringPayload(data []byte) (interface{}, error) {
+	var payload string
+	err := unmarshaller.Unmarshal(data, &payload)
+	return payload, err
+}
@@ -5,8 +5,33 @@ import (
 	"strings"
 )
 
-// ValidationError represents an error related to a set of LSIF input lines.
+// Severity describes how a validation error affects the outcome of a validation.
+type Severity string
+
+const (
+	// SeverityError marks errors that fail the validation.
+	SeverityError Severity = "error"
+	// SeverityWarning marks errors that are reported but do not fail the validation.
+	SeverityWarning Severity = "warning"
+	// SeverityOff marks rules that are not checked.
+	SeverityOff Severity = "off"
+)
+
+// ParseSeverity converts the given string into a severity.
+func ParseSeverity(value string) (Severity, error) {
+	switch severity := Severity(value); severity {
+	case SeverityError, SeverityWarning, SeverityOff:
+		return severity, nil
+	}
+
+	return "", fmt.Errorf("unknown severity %q (expected error, warning, or off)", value)
+}
+
+// ValidationError represents an error related to a set of LSIF input lines. Rule is the stable
+// identifier of the validation rule that raised the error.
 type ValidationError struct {
+	Rule          string
+	Severity      Severity
 	Message       string
 	RelevantLines []LineContext
 }
@@ -14,7 +39,8 @@ type ValidationError struct {
 // NewValidationError creates a new validation error with the given error message.
 func NewValidationError(format string, args ...interface{}) *ValidationError {
 	return &ValidationError{
-		Message: fmt.Sprintf(format, args...),
+		Severity: SeverityError,
+		Message:  fmt.Sprintf(format, args...),
 	}
 }
 
@@ -28,8 +54,20 @@ func (ve *ValidationError) AddContext(lineContexts ...LineContext) *ValidationEr
 func (ve *ValidationError) Error() string {
 	var contexts []string
 	for _, lineContext := range ve.RelevantLines {
-		contexts = append(contexts, fmt.Sprintf("\ton line #%d: %v", lineContext.Index, lineContext.Element))
+		if lineContext.Raw != "" {
+			contexts = append(contexts, fmt.Sprintf("\ton line #%d: %s", lineContext.Index, lineContext.Raw))
+		} else {
+			contexts = append(contexts, fmt.Sprintf("\ton line #%d: %v", lineContext.Index, lineContext.Element))
+		}
+	}
+
+	message := ve.Message
+	if ve.Rule != "" {
+		message = fmt.Sprintf("%s: %s", ve.Rule, message)
+	}
+	if ve.Severity == SeverityWarning {
+		message = fmt.Sprintf("warning: %s", message)
 	}
 
-	return strings.Join(append([]string{ve.Message}, contexts...), "\n")
+	return strings.Join(append([]string{message}, contexts...), "\n")
 }
@@ -0,0 +1,132 @@
+package reader
+
+import reader "github.com/sourcegraph/lsif-protocol/reader"
+
+// Graph is an index of the vertices and edges registered to a Stasher. It is built once after
+// the index has been read and provides the adjacency of each vertex as well as the vertices and
+// edges with a given label. Only edge identifiers are indexed; the adjacent vertices of an edge
+// are read from the Stasher. All identifier lists are in the order in which the elements were
+// registered to the Stasher.
+type Graph struct {
+	stasher         Stasher
+	outEdges        map[int][]int
+	inEdges         map[int][]int
+	edgesByLabel    map[string][]int
+	verticesByLabel map[string][]int
+}
+
+// NewGraph creates a Graph from the elements registered to the given Stasher. Elements registered
+// after the graph is created are not indexed.
+func NewGraph(stasher Stasher) *Graph {
+	g := &Graph{
+		stasher:         stasher,
+		outEdges:        map[int][]int{},
+		inEdges:         map[int][]int{},
+		edgesByLabel:    map[string][]int{},
+		verticesByLabel: map[string][]int{},
+	}
+
+	_ = stasher.VertexLabels(func(id int, label string) bool {
+		g.verticesByLabel[label] = append(g.verticesByLabel[label], id)
+		return true
+	})
+
+	_ = stasher.EdgeLabels(func(id int, label string, edge reader.Edge) bool {
+		g.edgesByLabel[label] = append(g.edgesByLabel[label], id)
+		g.outEdges[edge.OutV] = append(g.outEdges[edge.OutV], id)
+
+		return ForEachInV(edge, func(inV int) bool {
+			g.inEdges[inV] = append(g.inEdges[inV], id)
+			return true
+		})
+	})
+
+	return g
+}
+
+// Edge returns the adjacent vertices of the edge with the given identifier. The InVs slice of
+// the edge must not be modified.
+func (g *Graph) Edge(id int) (reader.Edge, bool) {
+	lineContext, ok := g.stasher.Edge(id)
+	if !ok {
+		return reader.Edge{}, false
+	}
+
+	edge, ok := lineContext.Element.Payload.(reader.Edge)
+	return edge, ok
+}
+
+// OutEdges returns the identifiers of the edges whose OutV property refers to the given vertex.
+func (g *Graph) OutEdges(vertexID int) []int {
+	return g.outEdges[vertexID]
+}
+
+// InEdges returns the identifiers of the edges whose InV or InVs properties refer to the given
+// vertex. An edge that refers to the vertex more than once is listed once for each reference.
+func (g *Graph) InEdges(vertexID int) []int {
+	return g.inEdges[vertexID]
+}
+
+// OutNeighbors returns the identifiers of the vertices referred to by the InV and InVs properties
+// of the out edges of the given vertex. The neighbors are not indexed and are collected from the
+// out edges on each call.
+func (g *Graph) OutNeighbors(vertexID int) (neighbors []int) {
+	for _, edgeID := range g.outEdges[vertexID] {
+		if edge, ok := g.Edge(edgeID); ok {
+			neighbors = append(neighbors, EachInV(edge)...)
+		}
+	}
+
+	return neighbors
+}
+
+// InNeighbors returns the identifiers of the vertices referred to by the OutV property of the in
+// edges of the given vertex. The neighbors are not indexed and are collected from the in edges on
+// each call.
+func (g *Graph) InNeighbors(vertexID int) (neighbors []int) {
+	for _, edgeID := range g.inEdges[vertexID] {
+		if edge, ok := g.Edge(edgeID); ok {
+			neighbors = append(neighbors, edge.OutV)
+		}
+	}
+
+	return neighbors
+}
+
+// EdgesWithLabel returns the identifiers of the edges with the given label.
+func (g *Graph) EdgesWithLabel(label string) []int {
+	return g.edgesByLabel[label]
+}
+
+// VerticesWithLabel returns the identifiers of the vertices with the given label.
+func (g *Graph) VerticesWithLabel(label string) []int {
+	return g.verticesByLabel[label]
+}
+
+// ForEachInV calls the given function on each sink vertex adjacent to the given
+// edge. If any invocation returns false, iteration of the adjacent vertices will
+// not complete and false will be returned immediately.
+func ForEachInV(edge reader.Edge, f func(inV int) bool) bool {
+	if edge.InV != 0 {
+		if !f(edge.InV) {
+			return false
+		}
+	}
+	for _, inV := range edge.InVs {
+		if !f(inV) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// EachInV returns a slice containing the InV/InVs values of the given edge.
+func EachInV(edge reader.Edge) (inVs []int) {
+	_ = ForEachInV(edge, func(inV int) bool {
+		inVs = append(inVs, inV)
+		return true
+	})
+
+	return inVs
+}
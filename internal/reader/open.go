@@ -0,0 +1,91 @@
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// StdinPath is the path that refers to the standard input stream.
+const StdinPath = "-"
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Open opens the LSIF index at the given path for reading. The path "-" refers to stdin. If the
+// index is compressed with gzip or zstd, the returned reader yields the decompressed index.
+func Open(path string) (io.ReadCloser, error) {
+	if path == StdinPath {
+		return Decompress(ioutil.NopCloser(os.Stdin))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := Decompress(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return rc, nil
+}
+
+// Decompress detects the compression of the given reader by its magic bytes and returns a reader
+// of the decompressed contents. Readers that are not gzip or zstd compressed are returned as-is.
+// Closing the returned reader also closes the given reader.
+func Decompress(rc io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(magic, gzipMagic) {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		return &decompressor{Reader: gr, close: gr.Close, underlying: rc}, nil
+	}
+
+	if bytes.HasPrefix(magic, zstdMagic) {
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		return &decompressor{Reader: zr, close: func() error { zr.Close(); return nil }, underlying: rc}, nil
+	}
+
+	return &decompressor{Reader: br, underlying: rc}, nil
+}
+
+// decompressor reads from a (possibly decompressing) reader and closes both that reader and the
+// underlying compressed stream.
+type decompressor struct {
+	io.Reader
+	close      func() error
+	underlying io.Closer
+}
+
+func (d *decompressor) Close() error {
+	if d.close != nil {
+		if err := d.close(); err != nil {
+			_ = d.underlying.Close()
+			return err
+		}
+	}
+
+	return d.underlying.Close()
+}
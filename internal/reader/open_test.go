@@ -0,0 +1,113 @@
+package reader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const openIndex = `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+`
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lsif-open-")
+	if err != nil {
+		t.Fatalf("unexpected error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		name     string
+		contents []byte
+		expected string
+	}{
+		{name: "plain.lsif", contents: []byte(openIndex), expected: openIndex},
+		{name: "gzip.lsif.gz", contents: compress(t, openIndex, gzipWriter), expected: openIndex},
+		{name: "zstd.lsif.zst", contents: compress(t, openIndex, zstdWriter), expected: openIndex},
+		{name: "short.lsif", contents: []byte("{\n"), expected: "{\n"},
+		{name: "empty.lsif", contents: nil, expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		path := filepath.Join(dir, testCase.name)
+		if err := ioutil.WriteFile(path, testCase.contents, 0644); err != nil {
+			t.Fatalf("unexpected error writing %s: %s", testCase.name, err)
+		}
+
+		if contents := readOpened(t, path); contents != testCase.expected {
+			t.Errorf("unexpected contents of %s: want %q, have %q", testCase.name, testCase.expected, contents)
+		}
+	}
+}
+
+func TestOpenStdin(t *testing.T) {
+	f, err := ioutil.TempFile("", "lsif-stdin-")
+	if err != nil {
+		t.Fatalf("unexpected error creating temporary file: %s", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := f.Write(compress(t, openIndex, gzipWriter)); err != nil {
+		t.Fatalf("unexpected error writing temporary file: %s", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("unexpected error seeking temporary file: %s", err)
+	}
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	if contents := readOpened(t, StdinPath); contents != openIndex {
+		t.Errorf("unexpected contents of stdin: want %q, have %q", openIndex, contents)
+	}
+}
+
+// readOpened returns the contents of the reader returned by Open for the given path.
+func readOpened(t *testing.T, path string) string {
+	rc, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening %s: %s", path, err)
+	}
+	defer rc.Close()
+
+	contents, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected error reading %s: %s", path, err)
+	}
+
+	return string(contents)
+}
+
+func gzipWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func zstdWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+// compress returns the given contents compressed by a writer created by the given function.
+func compress(t *testing.T, contents string, newWriter func(w io.Writer) (io.WriteCloser, error)) []byte {
+	var buf bytes.Buffer
+	w, err := newWriter(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %s", err)
+	}
+	if _, err := io.WriteString(w, contents); err != nil {
+		t.Fatalf("unexpected error compressing contents: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error compressing contents: %s", err)
+	}
+
+	return buf.Bytes()
+}
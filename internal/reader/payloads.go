@@ -0,0 +1,99 @@
+package reader
+
+import (
+	"encoding/json"
+	"fmt"
+
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+)
+
+// PackageInformation is the payload of a packageInformation vertex. It extends the payload
+// produced by the lsif-protocol reader with the package manager, which that reader does not
+// decode.
+type PackageInformation struct {
+	reader.PackageInformation
+	Manager string
+}
+
+// String formats the package information as the payload produced by the lsif-protocol reader.
+func (p PackageInformation) String() string {
+	return fmt.Sprint(p.PackageInformation)
+}
+
+// MarshalJSON encodes the package information as the payload produced by the lsif-protocol
+// reader, so that serialized payloads (e.g. in reports and error fingerprints) do not depend
+// on the fields added here.
+func (p PackageInformation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.PackageInformation)
+}
+
+// HoverResult is the payload of a hoverResult vertex. It extends the payload produced by the
+// lsif-protocol reader, which is the text of the hover, with the shape of the hover contents
+// and the optional range of the hover.
+type HoverResult struct {
+	// Contents is the text of the hover, as produced by the lsif-protocol reader.
+	Contents string
+	// IsArray is true if the hover contents are an array of MarkedStrings.
+	IsArray bool
+	// Parts describes each element of the hover contents, or the hover contents themselves if
+	// they are not an array.
+	Parts []HoverPart
+	// Range is the range of the hover, if one is given.
+	Range *reader.Range
+}
+
+// String returns the text of the hover.
+func (h HoverResult) String() string {
+	return h.Contents
+}
+
+// MarshalJSON encodes the text of the hover. See PackageInformation.MarshalJSON.
+func (h HoverResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.Contents)
+}
+
+// HoverPartShape is the JSON shape of an element of hover contents.
+type HoverPartShape int
+
+const (
+	// HoverPartNull is a null element.
+	HoverPartNull HoverPartShape = iota
+	// HoverPartString is a MarkedString given as a string.
+	HoverPartString
+	// HoverPartCodeBlock is a MarkedString given as an object with a language and a value.
+	HoverPartCodeBlock
+	// HoverPartMarkupContent is a MarkupContent object with a kind and a value.
+	HoverPartMarkupContent
+)
+
+// HoverPart describes a single element of hover contents.
+type HoverPart struct {
+	Shape HoverPartShape
+	// Kind is the kind of a MarkupContent element.
+	Kind string
+	// Blank is true if the element has no text other than whitespace.
+	Blank bool
+}
+
+// Event is the payload of a $event vertex. Data is the identifier of the document or project
+// whose scope begins or ends with the event.
+type Event struct {
+	Kind  string
+	Scope string
+	Data  int
+}
+
+// MarshalJSON encodes the kind and scope of the event, but not the identifier it refers to. See
+// PackageInformation.MarshalJSON.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind  string
+		Scope string
+	}{e.Kind, e.Scope})
+}
+
+// Project is the payload of a project vertex.
+type Project struct {
+	Kind string
+	Name string
+}
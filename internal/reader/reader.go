@@ -1,8 +1,12 @@
 package reader
 
 import (
-	"context"
+	"bufio"
+	"bytes"
+	"fmt"
 	"io"
+	"runtime"
+	"strconv"
 
 	reader "github.com/sourcegraph/lsif-protocol/reader"
 )
@@ -10,38 +14,277 @@ import (
 // ElementMapper is the type of function that is invoked for each parsed element.
 type ElementMapper func(lineContext LineContext)
 
+// ErrorMapper is the type of function that is invoked for each line that could not be parsed.
+type ErrorMapper func(err *ValidationError)
+
+// readBatchSize is the number of lines decoded together by a single decoder goroutine.
+const readBatchSize = 512
+
+// maxLineSize is the length in bytes of the longest line that is decoded. Longer lines are
+// reported as malformed.
+const maxLineSize int = reader.LineBufferSize
+
+// oversizedLinePrefix is the number of leading bytes of a line longer than maxLineSize that are
+// kept as the raw context of its error.
+const oversizedLinePrefix = 1024
+
 // Read consumes the given reader as newline-delimited JSON-encoded LSIF. Each parsed vertex and each
 // parsed edge element is registered to the given Stasher. If vertex or edge mappers are supplied, they
-// are invoked on each parsed element.
-func Read(r io.Reader, stasher *Stasher, vertexMapper, edgeMapper ElementMapper) error {
-	index := 0
-	for pair := range reader.Read(context.Background(), r) {
-		if pair.Err != nil {
-			return pair.Err
+// are invoked on each parsed element. Lines that cannot be parsed are skipped; if an error mapper is
+// supplied, it is invoked with an error describing each such line. An error is returned only if the
+// given reader cannot be read.
+//
+// Lines are decoded concurrently, but the mappers are invoked and elements are registered from the
+// calling goroutine in the order in which they occur in the input.
+func Read(r io.Reader, stasher Stasher, vertexMapper, edgeMapper ElementMapper, errorMapper ErrorMapper) error {
+	numDecoders := runtime.GOMAXPROCS(0)
+	interner := reader.NewInterner()
+
+	// Batches are sent to the decoders and, in input order, to the consumer below. The consumer
+	// waits for each batch to be decoded before processing it.
+	work := make(chan *lineBatch, numDecoders)
+	ordered := make(chan *lineBatch, numDecoders*2)
+
+	var scanErr error
+	go func() {
+		defer close(ordered)
+		defer close(work)
+
+		scanErr = scanBatches(r, func(batch *lineBatch) {
+			ordered <- batch
+			work <- batch
+		})
+	}()
+
+	for i := 0; i < numDecoders; i++ {
+		go func() {
+			for batch := range work {
+				batch.decode(interner)
+			}
+		}()
+	}
+
+	for batch := range ordered {
+		<-batch.done
+
+		for i, index := range batch.indexes {
+			if batch.deferred[i] {
+				var header elementHeader
+				batch.elements[i], header, batch.errs[i] = unmarshalElement(interner, batch.lines[i])
+				batch.itemProperties[i] = itemProperty(header)
+			}
+
+			if err := batch.errs[i]; err != nil {
+				if errorMapper != nil {
+					errorMapper(NewValidationError("malformed line: %s", err).AddContext(LineContext{
+						Index: index,
+						Raw:   string(batch.lines[i]),
+					}))
+				}
+
+				continue
+			}
+
+			lineContext := LineContext{
+				Index:        index,
+				Element:      batch.elements[i],
+				Properties:   batch.properties[i],
+				ItemProperty: batch.itemProperties[i],
+			}
+
+			if lineContext.Element.Type == "vertex" {
+				if vertexMapper != nil {
+					vertexMapper(lineContext)
+				}
+
+				stasher.StashVertex(lineContext)
+			}
+
+			if lineContext.Element.Type == "edge" {
+				if edgeMapper != nil {
+					edgeMapper(lineContext)
+				}
+
+				stasher.StashEdge(lineContext)
+			}
+		}
+	}
+
+	return scanErr
+}
+
+// lineBatch is a sequence of non-empty lines of the input along with their decoded elements. The
+// done channel is closed once the lines have been decoded. Deferred lines could not be decoded
+// concurrently and must be decoded by the consumer of the batch.
+type lineBatch struct {
+	indexes        []int
+	lines          [][]byte
+	elements       []reader.Element
+	properties     [][]string
+	itemProperties []string
+	oversized      []bool
+	errs           []error
+	deferred       []bool
+	done           chan struct{}
+}
+
+// decode unmarshals each line of the batch and signals its completion.
+func (b *lineBatch) decode(interner *reader.Interner) {
+	b.elements = make([]reader.Element, len(b.lines))
+	b.properties = make([][]string, len(b.lines))
+	b.itemProperties = make([]string, len(b.lines))
+	b.errs = make([]error, len(b.lines))
+	b.deferred = make([]bool, len(b.lines))
+
+	for i, line := range b.lines {
+		if b.oversized[i] {
+			b.errs[i] = fmt.Errorf("line exceeds the maximum length of %d bytes", maxLineSize)
+			continue
+		}
+
+		numericInterner := &numericInterner{interner: interner}
+		var header elementHeader
+		b.elements[i], header, b.errs[i] = unmarshalElement(numericInterner, line)
+		b.deferred[i] = numericInterner.deferred
+
+		if b.errs[i] == nil {
+			b.properties[i] = header.names
+
+			b.itemProperties[i] = itemProperty(header)
+		}
+	}
+
+	close(b.done)
+}
+
+// itemProperty returns the value of the property field of the given element if it is an item edge.
+func itemProperty(header elementHeader) string {
+	if header.elementType == "edge" && header.label == "item" {
+		return header.property
+	}
+
+	return ""
+}
+
+// numericInterner interns identifiers that are numbers or numeric strings, which does not depend
+// on the order in which identifiers are interned. The identifiers that reader.Interner assigns to
+// other strings depend on the order in which they are first seen, so such identifiers are not
+// interned; deferred is set instead so that the line can be decoded again in input order.
+type numericInterner struct {
+	interner *reader.Interner
+	deferred bool
+}
+
+func (i *numericInterner) Intern(raw []byte) (int, error) {
+	if len(raw) >= 2 && raw[0] == '"' {
+		if _, err := strconv.Atoi(string(raw[1 : len(raw)-1])); err != nil {
+			i.deferred = true
+			return 0, nil
+		}
+	}
+
+	return i.interner.Intern(raw)
+}
+
+// scanBatches splits the given reader into batches of non-empty lines and invokes the given
+// function with each batch in input order. Lines longer than maxLineSize are truncated to their
+// first oversizedLinePrefix bytes and marked as oversized. The returned error is the error of the
+// underlying reader, if any.
+func scanBatches(r io.Reader, f func(batch *lineBatch)) error {
+	br := bufio.NewReader(r)
+
+	var (
+		index     int
+		indexes   []int
+		offsets   []int
+		oversized []bool
+		buf       []byte
+	)
+
+	// The lines of a batch share a single buffer, as the reader reuses its own.
+	emit := func() {
+		lines := make([][]byte, len(indexes))
+		for i := range indexes {
+			lines[i] = buf[offsets[i]:offsets[i+1]:offsets[i+1]]
+		}
+
+		f(&lineBatch{indexes: indexes, lines: lines, oversized: oversized, done: make(chan struct{})})
+		indexes, offsets, oversized, buf = nil, nil, nil, nil
+	}
+
+	for {
+		line, truncated, err := readLine(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
 		}
 
 		index++
-		lineContext := LineContext{
-			Index:   index,
-			Element: pair.Element,
+		if len(line) == 0 && !truncated {
+			continue
+		}
+		if truncated && len(line) > oversizedLinePrefix {
+			line = line[:oversizedLinePrefix]
 		}
 
-		if pair.Element.Type == "vertex" {
-			if vertexMapper != nil {
-				vertexMapper(lineContext)
-			}
+		if len(offsets) == 0 {
+			offsets = append(offsets, 0)
+		}
+		buf = append(buf, line...)
+		indexes = append(indexes, index)
+		offsets = append(offsets, len(buf))
+		oversized = append(oversized, truncated)
 
-			stasher.StashVertex(lineContext)
+		if len(indexes) == readBatchSize {
+			emit()
 		}
+	}
+
+	if len(indexes) > 0 {
+		emit()
+	}
 
-		if pair.Element.Type == "edge" {
-			if edgeMapper != nil {
-				edgeMapper(lineContext)
+	return nil
+}
+
+// readLine reads the next line of the given reader without its line terminator. If the line is
+// longer than maxLineSize, only its first maxLineSize bytes are returned, the rest of the line is
+// discarded, and truncated is true. The returned line is valid until the next read. This function
+// returns io.EOF only once the reader is exhausted and no line remains.
+func readLine(r *bufio.Reader) (line []byte, truncated bool, err error) {
+	chunk, err := r.ReadSlice('\n')
+	if err == nil {
+		return trimLineTerminator(chunk), false, nil
+	}
+
+	for {
+		if !truncated {
+			if len(line)+len(chunk) > maxLineSize {
+				line = append(line, chunk[:maxLineSize-len(line)]...)
+				truncated = true
+			} else {
+				line = append(line, chunk...)
 			}
+		}
 
-			stasher.StashEdge(lineContext)
+		if err != bufio.ErrBufferFull {
+			break
 		}
+		chunk, err = r.ReadSlice('\n')
 	}
 
-	return nil
+	if err == io.EOF && (len(line) > 0 || truncated) {
+		err = nil
+	}
+
+	return trimLineTerminator(line), truncated, err
+}
+
+// trimLineTerminator removes a trailing newline, and a carriage return preceding it, from the
+// given line.
+func trimLineTerminator(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	return bytes.TrimSuffix(line, []byte{'\r'})
 }
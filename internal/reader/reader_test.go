@@ -0,0 +1,46 @@
+package reader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadOversizedLine(t *testing.T) {
+	oversized := `{"id":3,"type":"vertex","label":"hoverResult","result":{"contents":"` + strings.Repeat("a", maxLineSize) + `"}}`
+
+	index := strings.Join([]string{
+		`{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}`,
+		`{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}`,
+		oversized,
+		`{"id":4,"type":"vertex","label":"range","start":{"line":1,"character":0},"end":{"line":1,"character":4}}`,
+		`{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[4]}`,
+	}, "\n")
+
+	var errs []*ValidationError
+	stasher := NewStasher()
+	if err := Read(strings.NewReader(index), stasher, nil, nil, func(err *ValidationError) { errs = append(errs, err) }); err != nil {
+		t.Fatalf("unexpected error reading index: %s", err)
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("unexpected number of errors: want 1, have %d", len(errs))
+	}
+	if expected := "malformed line: line exceeds the maximum length of 10000000 bytes"; errs[0].Message != expected {
+		t.Errorf("unexpected message: want %q, have %q", expected, errs[0].Message)
+	}
+	if lineContext := errs[0].RelevantLines[0]; lineContext.Index != 3 || lineContext.Raw != oversized[:oversizedLinePrefix] {
+		t.Errorf("unexpected line context: line #%d with %d bytes", lineContext.Index, len(lineContext.Raw))
+	}
+
+	for _, id := range []int{1, 2, 4} {
+		if _, ok := stasher.Vertex(id); !ok {
+			t.Errorf("expected vertex %d to be read", id)
+		}
+	}
+	if _, ok := stasher.Vertex(3); ok {
+		t.Errorf("expected oversized vertex to be skipped")
+	}
+	if lineContext, ok := stasher.Edge(5); !ok || lineContext.Index != 5 {
+		t.Errorf("expected edge 5 to be read from line #5")
+	}
+}
@@ -1,26 +1,131 @@
 package reader
 
-import reader "github.com/sourcegraph/lsif-protocol/reader"
+import (
+	"math"
 
-// Stasher maintains a mapping from identifiers to vertex and edge elements.
-type Stasher struct {
-	vertices map[int]LineContext
-	edges    map[int]LineContext
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+)
+
+// Stasher maintains a mapping from identifiers to vertex and edge elements. Elements are
+// iterated in the order in which they were registered.
+type Stasher interface {
+	// Vertices invokes the given function on each registered vertex in registration order. If any
+	// invocation returns false, iteration of the vertices will not complete and false will be
+	// returned immediately.
+	Vertices(f func(lineContext LineContext) bool) bool
+
+	// Edges invokes the given function on each registered edge in registration order. If any
+	// invocation returns false, iteration of the edges will not complete and false will be
+	// returned immediately.
+	Edges(f func(lineContext LineContext, edge reader.Edge) bool) bool
+
+	// Vertex returns a vertex element by its identifier.
+	Vertex(id int) (LineContext, bool)
+
+	// Edge returns a edge element by its identifier.
+	Edge(id int) (LineContext, bool)
+
+	// VertexLabels invokes the given function with the identifier and label of each registered
+	// vertex in registration order. Unlike Vertices, this does not materialize vertex payloads.
+	// If any invocation returns false, iteration will not complete and false will be returned
+	// immediately.
+	VertexLabels(f func(id int, label string) bool) bool
+
+	// EdgeLabels invokes the given function with the identifier, label, and adjacent vertices of
+	// each registered edge in registration order. The InVs slice of the edge must not be modified.
+	// If any invocation returns false, iteration will not complete and false will be returned
+	// immediately.
+	EdgeLabels(f func(id int, label string, edge reader.Edge) bool) bool
+
+	// VertexLabel returns the label of a vertex by its identifier.
+	VertexLabel(id int) (string, bool)
+
+	// Range returns the bounds of a range vertex by its identifier.
+	Range(id int) (reader.Range, bool)
+
+	// StashVertex registers a vertex element. This method may fail if another vertex or edge has
+	// already been registered with the same identifier.
+	StashVertex(lineContext LineContext) *ValidationError
+
+	// StashEdge registers an edge element. This method may fail if another vertex or edge has
+	// already been registered with the same identifier.
+	StashEdge(lineContext LineContext) *ValidationError
+
+	// Close releases any resources held by the Stasher.
+	Close() error
+}
+
+// payloadKind identifies the column that holds the payload of an element.
+type payloadKind uint8
+
+const (
+	payloadNone   payloadKind = iota // no payload
+	payloadRange                     // ranges[ref]
+	payloadString                    // strings[ref]
+	payloadEdge                      // edges[ref]
+	payloadOther                     // others[ref]
+	payloadSpill                     // spilled to disk, see spill.read
+)
+
+// columnStasher is a Stasher that stores elements in typed columns. Each element is assigned a
+// slot in registration order, and the columns of the element table are indexed by slot. Labels
+// are interned as small integers, and the payloads of ranges, strings (document URIs and hover
+// text), and edges are stored unboxed in their own columns.
+//
+// If spill is non-nil, vertex payloads are written to disk instead of held in memory.
+type columnStasher struct {
+	slots  idIndex
+	labels labelTable
+
+	// element table, indexed by slot
+	ids      []int
+	indexes  []int32
+	isEdge   []bool
+	labelIDs []uint32
+	kinds    []payloadKind
+	refs     []int32
+
+	// slots of vertices and edges in registration order
+	vertexSlots []int32
+	edgeSlots   []int32
+
+	// payload columns
+	ranges  []reader.Range
+	strings []string
+	edges   []compactEdge
+	inVs    []int
+	others  []interface{}
+
+	spill *payloadFile
+}
+
+// compactEdge is the adjacency of an edge. The InVs of the edge are inVs[inVsStart:inVsEnd].
+// The property field of an item edge is interned in the label table.
+type compactEdge struct {
+	outV      int
+	inV       int
+	document  int
+	inVsStart int32
+	inVsEnd   int32
+	property  uint32
 }
 
-// NewStasher creates a new empty Stasher.
-func NewStasher() *Stasher {
-	return &Stasher{
-		vertices: map[int]LineContext{},
-		edges:    map[int]LineContext{},
+// NewStasher creates a new empty Stasher that holds every element in memory.
+func NewStasher() Stasher {
+	return newColumnStasher(nil)
+}
+
+func newColumnStasher(spill *payloadFile) *columnStasher {
+	return &columnStasher{
+		slots:  newIDIndex(),
+		labels: newLabelTable(),
+		spill:  spill,
 	}
 }
 
-// Vertices invokes the given function on each registered vertex. If any invocation returns false,
-// iteration of the vertices will not complete and false will be returned immediately.
-func (s *Stasher) Vertices(f func(lineContext LineContext) bool) bool {
-	for _, lineContext := range s.vertices {
-		if !f(lineContext) {
+func (s *columnStasher) Vertices(f func(lineContext LineContext) bool) bool {
+	for _, slot := range s.vertexSlots {
+		if !f(s.lineContext(slot)) {
 			return false
 		}
 	}
@@ -28,10 +133,9 @@ func (s *Stasher) Vertices(f func(lineContext LineContext) bool) bool {
 	return true
 }
 
-// Edges invokes the given function on each registered edge. If any invocation returns false,
-// iteration of the edges will not complete and false will be returned immediately.
-func (s *Stasher) Edges(f func(lineContext LineContext, edge reader.Edge) bool) bool {
-	for _, lineContext := range s.edges {
+func (s *columnStasher) Edges(f func(lineContext LineContext, edge reader.Edge) bool) bool {
+	for _, slot := range s.edgeSlots {
+		lineContext := s.lineContext(slot)
 		edge, ok := lineContext.Element.Payload.(reader.Edge)
 		if !ok {
 			continue
@@ -45,47 +149,228 @@ func (s *Stasher) Edges(f func(lineContext LineContext, edge reader.Edge) bool)
 	return true
 }
 
-// Vertex returns a vertex element by its identifier.
-func (s *Stasher) Vertex(id int) (LineContext, bool) {
-	v, ok := s.vertices[id]
-	return v, ok
+func (s *columnStasher) Vertex(id int) (LineContext, bool) {
+	slot, ok := s.slots.get(id)
+	if !ok || s.isEdge[slot] {
+		return LineContext{}, false
+	}
+
+	return s.lineContext(slot), true
+}
+
+func (s *columnStasher) Edge(id int) (LineContext, bool) {
+	slot, ok := s.slots.get(id)
+	if !ok || !s.isEdge[slot] {
+		return LineContext{}, false
+	}
+
+	return s.lineContext(slot), true
+}
+
+func (s *columnStasher) VertexLabels(f func(id int, label string) bool) bool {
+	for _, slot := range s.vertexSlots {
+		if !f(s.ids[slot], s.labels.get(s.labelIDs[slot])) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *columnStasher) EdgeLabels(f func(id int, label string, edge reader.Edge) bool) bool {
+	for _, slot := range s.edgeSlots {
+		if s.kinds[slot] != payloadEdge {
+			continue
+		}
+
+		if !f(s.ids[slot], s.labels.get(s.labelIDs[slot]), s.edge(s.refs[slot])) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *columnStasher) VertexLabel(id int) (string, bool) {
+	slot, ok := s.slots.get(id)
+	if !ok || s.isEdge[slot] {
+		return "", false
+	}
+
+	return s.labels.get(s.labelIDs[slot]), true
 }
 
-// Edge returns a edge element by its identifier.
-func (s *Stasher) Edge(id int) (LineContext, bool) {
-	v, ok := s.edges[id]
-	return v, ok
+func (s *columnStasher) Range(id int) (reader.Range, bool) {
+	slot, ok := s.slots.get(id)
+	if !ok || s.isEdge[slot] {
+		return reader.Range{}, false
+	}
+
+	r, ok := s.payload(slot).(reader.Range)
+	return r, ok
 }
 
-// StashVertex registers a vertex element. This method may fail if another vertex or edge has already
-// been registered with the same identifier.
-func (s *Stasher) StashVertex(lineContext LineContext) *ValidationError {
+func (s *columnStasher) StashVertex(lineContext LineContext) *ValidationError {
 	if err := s.checkIdentifier(lineContext); err != nil {
 		return err
 	}
 
-	s.vertices[lineContext.Element.ID] = lineContext
+	slot := s.add(lineContext, false)
+	s.vertexSlots = append(s.vertexSlots, slot)
 	return nil
 }
 
-// StashEdge registers an edge element. This method may fail if another vertex or edge has already
-// been registered with the same identifier.
-func (s *Stasher) StashEdge(lineContext LineContext) *ValidationError {
+func (s *columnStasher) StashEdge(lineContext LineContext) *ValidationError {
 	if err := s.checkIdentifier(lineContext); err != nil {
 		return err
 	}
 
-	s.edges[lineContext.Element.ID] = lineContext
+	slot := s.add(lineContext, true)
+	s.edgeSlots = append(s.edgeSlots, slot)
 	return nil
 }
 
-func (s *Stasher) checkIdentifier(lineContext LineContext) *ValidationError {
-	if other, ok := s.vertices[lineContext.Element.ID]; ok {
-		return NewValidationError("identifier already exists").AddContext(lineContext, other)
+func (s *columnStasher) Close() error {
+	if s.spill != nil {
+		return s.spill.close()
 	}
-	if other, ok := s.edges[lineContext.Element.ID]; ok {
-		return NewValidationError("identifier already exists").AddContext(lineContext, other)
+
+	return nil
+}
+
+func (s *columnStasher) checkIdentifier(lineContext LineContext) *ValidationError {
+	if slot, ok := s.slots.get(lineContext.Element.ID); ok {
+		return NewValidationError("identifier already exists").AddContext(lineContext, s.lineContext(slot))
 	}
 
 	return nil
 }
+
+// add appends the given element to the element table and returns its slot.
+func (s *columnStasher) add(lineContext LineContext, isEdge bool) int32 {
+	slot := int32(len(s.ids))
+	kind, ref := s.addPayload(lineContext, isEdge)
+
+	s.ids = append(s.ids, lineContext.Element.ID)
+	s.indexes = append(s.indexes, clampInt32(lineContext.Index))
+	s.isEdge = append(s.isEdge, isEdge)
+	s.labelIDs = append(s.labelIDs, s.labels.intern(lineContext.Element.Label))
+	s.kinds = append(s.kinds, kind)
+	s.refs = append(s.refs, ref)
+	s.slots.set(lineContext.Element.ID, slot)
+	return slot
+}
+
+// addPayload stores the payload of the given element in the column appropriate for its type and
+// returns the column and the payload's index within it.
+func (s *columnStasher) addPayload(lineContext LineContext, isEdge bool) (payloadKind, int32) {
+	element := lineContext.Element
+
+	switch payload := element.Payload.(type) {
+	case nil:
+		return payloadNone, 0
+
+	case reader.Edge:
+		start := int32(len(s.inVs))
+		s.inVs = append(s.inVs, payload.InVs...)
+		s.edges = append(s.edges, compactEdge{
+			outV:      payload.OutV,
+			inV:       payload.InV,
+			document:  payload.Document,
+			inVsStart: start,
+			inVsEnd:   int32(len(s.inVs)),
+			property:  s.labels.intern(lineContext.ItemProperty),
+		})
+		return payloadEdge, int32(len(s.edges) - 1)
+	}
+
+	if s.spill != nil && !isEdge {
+		if ref, ok := s.spill.write(element); ok {
+			return payloadSpill, ref
+		}
+	}
+
+	switch payload := element.Payload.(type) {
+	case reader.Range:
+		s.ranges = append(s.ranges, payload)
+		return payloadRange, int32(len(s.ranges) - 1)
+
+	case string:
+		s.strings = append(s.strings, payload)
+		return payloadString, int32(len(s.strings) - 1)
+	}
+
+	s.others = append(s.others, element.Payload)
+	return payloadOther, int32(len(s.others) - 1)
+}
+
+// lineContext materializes the element in the given slot.
+func (s *columnStasher) lineContext(slot int32) LineContext {
+	elementType := "vertex"
+	if s.isEdge[slot] {
+		elementType = "edge"
+	}
+
+	var itemProperty string
+	if s.kinds[slot] == payloadEdge {
+		itemProperty = s.labels.get(s.edges[s.refs[slot]].property)
+	}
+
+	return LineContext{
+		Index: int(s.indexes[slot]),
+		Element: reader.Element{
+			ID:      s.ids[slot],
+			Type:    elementType,
+			Label:   s.labels.get(s.labelIDs[slot]),
+			Payload: s.payload(slot),
+		},
+		ItemProperty: itemProperty,
+	}
+}
+
+// payload returns the payload of the element in the given slot.
+func (s *columnStasher) payload(slot int32) interface{} {
+	ref := s.refs[slot]
+
+	switch s.kinds[slot] {
+	case payloadRange:
+		return s.ranges[ref]
+	case payloadString:
+		return s.strings[ref]
+	case payloadEdge:
+		return s.edge(ref)
+	case payloadOther:
+		return s.others[ref]
+	case payloadSpill:
+		return s.spill.read(ref, s.labels.get(s.labelIDs[slot]))
+	}
+
+	return nil
+}
+
+// edge returns the edge stored at the given index of the edge column.
+func (s *columnStasher) edge(ref int32) reader.Edge {
+	e := s.edges[ref]
+
+	var inVs []int
+	if e.inVsEnd > e.inVsStart {
+		inVs = s.inVs[e.inVsStart:e.inVsEnd:e.inVsEnd]
+	}
+
+	return reader.Edge{
+		OutV:     e.outV,
+		InV:      e.inV,
+		InVs:     inVs,
+		Document: e.document,
+	}
+}
+
+// clampInt32 converts the given line number to an int32. Line numbers beyond the range of an
+// int32 (indexes with more than two billion lines) are saturated.
+func clampInt32(v int) int32 {
+	if v > math.MaxInt32 {
+		return math.MaxInt32
+	}
+
+	return int32(v)
+}
@@ -0,0 +1,324 @@
+package reader
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"testing"
+
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+	"github.com/sourcegraph/lsif-test/internal/testindex"
+)
+
+// stasherLines are the elements stored by the Stasher tests, one per line. They cover each kind of
+// payload column, edges with InV and InVs, an item edge with a property, repeated labels, and an
+// identifier that is too large to be indexed densely.
+var stasherLines = []string{
+	`{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}`,
+	`{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}`,
+	`{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":2},"end":{"line":1,"character":5}}`,
+	`{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3,10000000]}`,
+	`{"id":10000000,"type":"vertex","label":"range","start":{"line":2,"character":0},"end":{"line":2,"character":3}}`,
+	`{"id":5,"type":"vertex","label":"resultSet"}`,
+	`{"id":6,"type":"edge","label":"next","outV":3,"inV":5}`,
+	`{"id":7,"type":"vertex","label":"hoverResult","result":{"contents":{"kind":"markdown","value":"func main()"}}}`,
+	`{"id":8,"type":"edge","label":"textDocument/hover","outV":5,"inV":7}`,
+	`{"id":9,"type":"vertex","label":"moniker","kind":"export","scheme":"gomod","identifier":"main"}`,
+	`{"id":10,"type":"vertex","label":"packageInformation","name":"project","manager":"gomod","version":"v1.0.0"}`,
+	`{"id":11,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":2}`,
+	`{"id":12,"type":"vertex","label":"definitionResult"}`,
+	`{"id":13,"type":"edge","label":"item","outV":12,"inVs":[3],"document":2,"property":"definitions"}`,
+}
+
+// newTestStashers returns an in-memory and a disk-backed Stasher, keyed by name.
+func newTestStashers(t *testing.T) map[string]Stasher {
+	diskStasher, err := NewDiskStasher("")
+	if err != nil {
+		t.Fatalf("unexpected error creating disk stasher: %s", err)
+	}
+
+	return map[string]Stasher{"memory": NewStasher(), "disk": diskStasher}
+}
+
+// stashLines decodes the given lines and registers each element to the given Stasher. The
+// returned line contexts are in registration order.
+func stashLines(t *testing.T, stasher Stasher, lines []string) []LineContext {
+	interner := reader.NewInterner()
+
+	lineContexts := make([]LineContext, 0, len(lines))
+	for i, line := range lines {
+		element, header, err := unmarshalElement(interner, []byte(line))
+		if err != nil {
+			t.Fatalf("unexpected error decoding line %d: %s", i+1, err)
+		}
+
+		lineContext := LineContext{Index: i + 1, Element: element, ItemProperty: itemProperty(header)}
+		stash := stasher.StashVertex
+		if element.Type == "edge" {
+			stash = stasher.StashEdge
+		}
+		if err := stash(lineContext); err != nil {
+			t.Fatalf("unexpected error stashing line %d: %s", i+1, err)
+		}
+
+		lineContexts = append(lineContexts, lineContext)
+	}
+
+	return lineContexts
+}
+
+func TestStasherElements(t *testing.T) {
+	for name, stasher := range newTestStashers(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, expected := range stashLines(t, stasher, stasherLines) {
+				lookup, other := stasher.Vertex, stasher.Edge
+				if expected.Element.Type == "edge" {
+					lookup, other = stasher.Edge, stasher.Vertex
+				}
+
+				if lineContext, ok := lookup(expected.Element.ID); !ok || !reflect.DeepEqual(lineContext, expected) {
+					t.Errorf("unexpected element %d: want %+v, have %+v", expected.Element.ID, expected, lineContext)
+				}
+				if _, ok := other(expected.Element.ID); ok {
+					t.Errorf("unexpected %s with identifier %d", expected.Element.Type, expected.Element.ID)
+				}
+			}
+
+			if _, ok := stasher.Vertex(14); ok {
+				t.Errorf("unexpected vertex with unknown identifier")
+			}
+			if _, ok := stasher.Edge(14); ok {
+				t.Errorf("unexpected edge with unknown identifier")
+			}
+
+			if err := stasher.Close(); err != nil {
+				t.Errorf("unexpected error closing stasher: %s", err)
+			}
+		})
+	}
+}
+
+func TestStasherEdges(t *testing.T) {
+	testCases := []struct {
+		id           int
+		expected     reader.Edge
+		itemProperty string
+	}{
+		{id: 4, expected: reader.Edge{OutV: 2, InVs: []int{3, 10000000}}},
+		{id: 6, expected: reader.Edge{OutV: 3, InV: 5}},
+		{id: 13, expected: reader.Edge{OutV: 12, InVs: []int{3}, Document: 2}, itemProperty: "definitions"},
+	}
+
+	for name, stasher := range newTestStashers(t) {
+		t.Run(name, func(t *testing.T) {
+			defer stasher.Close()
+			stashLines(t, stasher, stasherLines)
+
+			edges := map[int]reader.Edge{}
+			_ = stasher.EdgeLabels(func(id int, label string, edge reader.Edge) bool {
+				edges[id] = edge
+				return true
+			})
+
+			for _, testCase := range testCases {
+				if edge := edges[testCase.id]; !reflect.DeepEqual(edge, testCase.expected) {
+					t.Errorf("unexpected edge %d: want %+v, have %+v", testCase.id, testCase.expected, edge)
+				}
+				lineContext, _ := stasher.Edge(testCase.id)
+				if !reflect.DeepEqual(lineContext.Element.Payload, testCase.expected) {
+					t.Errorf("unexpected payload of edge %d: want %+v, have %+v", testCase.id, testCase.expected, lineContext.Element.Payload)
+				}
+				if lineContext.ItemProperty != testCase.itemProperty {
+					t.Errorf("unexpected item property of edge %d: want %q, have %q", testCase.id, testCase.itemProperty, lineContext.ItemProperty)
+				}
+			}
+		})
+	}
+}
+
+func TestStasherLabelsAndRanges(t *testing.T) {
+	testCases := []struct {
+		id    int
+		label string
+		ok    bool
+		r     *reader.Range
+	}{
+		{id: 1, label: "metaData", ok: true},
+		{id: 3, label: "range", ok: true, r: &reader.Range{StartLine: 1, StartCharacter: 2, EndLine: 1, EndCharacter: 5}},
+		{id: 10000000, label: "range", ok: true, r: &reader.Range{StartLine: 2, EndLine: 2, EndCharacter: 3}},
+		{id: 11, label: "$event", ok: true},
+		{id: 4, ok: false},  // an edge
+		{id: 14, ok: false}, // unknown
+		{id: -1, ok: false}, // unknown
+	}
+
+	for name, stasher := range newTestStashers(t) {
+		t.Run(name, func(t *testing.T) {
+			defer stasher.Close()
+			stashLines(t, stasher, stasherLines)
+
+			for _, testCase := range testCases {
+				if label, ok := stasher.VertexLabel(testCase.id); label != testCase.label || ok != testCase.ok {
+					t.Errorf("unexpected label of %d: want %q (%v), have %q (%v)", testCase.id, testCase.label, testCase.ok, label, ok)
+				}
+
+				r, ok := stasher.Range(testCase.id)
+				if testCase.r == nil {
+					if ok {
+						t.Errorf("unexpected range %d: %+v", testCase.id, r)
+					}
+				} else if !ok || !reflect.DeepEqual(r, *testCase.r) {
+					t.Errorf("unexpected range %d: want %+v, have %+v", testCase.id, *testCase.r, r)
+				}
+			}
+		})
+	}
+}
+
+func TestStasherIterationOrder(t *testing.T) {
+	for name, stasher := range newTestStashers(t) {
+		t.Run(name, func(t *testing.T) {
+			defer stasher.Close()
+
+			var expectedVertices, expectedEdges []int
+			for _, lineContext := range stashLines(t, stasher, stasherLines) {
+				if lineContext.Element.Type == "edge" {
+					expectedEdges = append(expectedEdges, lineContext.Element.ID)
+				} else {
+					expectedVertices = append(expectedVertices, lineContext.Element.ID)
+				}
+			}
+
+			var vertices, vertexLabels, edges, edgeLabels []int
+			_ = stasher.Vertices(func(lineContext LineContext) bool {
+				vertices = append(vertices, lineContext.Element.ID)
+				return true
+			})
+			_ = stasher.VertexLabels(func(id int, label string) bool {
+				vertexLabels = append(vertexLabels, id)
+				return true
+			})
+			_ = stasher.Edges(func(lineContext LineContext, edge reader.Edge) bool {
+				edges = append(edges, lineContext.Element.ID)
+				return true
+			})
+			_ = stasher.EdgeLabels(func(id int, label string, edge reader.Edge) bool {
+				edgeLabels = append(edgeLabels, id)
+				return true
+			})
+
+			for _, ids := range [][]int{vertices, vertexLabels} {
+				if !reflect.DeepEqual(ids, expectedVertices) {
+					t.Errorf("unexpected vertex order: want %v, have %v", expectedVertices, ids)
+				}
+			}
+			for _, ids := range [][]int{edges, edgeLabels} {
+				if !reflect.DeepEqual(ids, expectedEdges) {
+					t.Errorf("unexpected edge order: want %v, have %v", expectedEdges, ids)
+				}
+			}
+
+			var visited int
+			if stasher.Vertices(func(lineContext LineContext) bool { visited++; return visited < 2 }) || visited != 2 {
+				t.Errorf("expected iteration to stop after 2 vertices, visited %d", visited)
+			}
+		})
+	}
+}
+
+func TestStasherDuplicateIdentifiers(t *testing.T) {
+	for name, stasher := range newTestStashers(t) {
+		t.Run(name, func(t *testing.T) {
+			defer stasher.Close()
+			stashLines(t, stasher, stasherLines[:3])
+
+			duplicate := LineContext{Index: 4, Element: reader.Element{ID: 3, Type: "edge", Label: "next", Payload: reader.Edge{OutV: 3, InV: 2}}}
+			err := stasher.StashEdge(duplicate)
+			if err == nil || err.Message != "identifier already exists" || len(err.RelevantLines) != 2 || err.RelevantLines[1].Index != 3 {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if lineContext, _ := stasher.Vertex(3); lineContext.Element.Label != "range" {
+				t.Errorf("unexpected element replacing vertex 3: %+v", lineContext)
+			}
+		})
+	}
+}
+
+func TestLabelTable(t *testing.T) {
+	labels := newLabelTable()
+	first, second, again := labels.intern("range"), labels.intern("next"), labels.intern("range")
+
+	if first != again || first == second {
+		t.Errorf("unexpected label identifiers: range=%d next=%d range=%d", first, second, again)
+	}
+	if labels.get(first) != "range" || labels.get(second) != "next" {
+		t.Errorf("unexpected labels: %q, %q", labels.get(first), labels.get(second))
+	}
+}
+
+// benchmarkElements is the approximate number of elements in the index read by each iteration
+// of the Stasher benchmarks.
+const benchmarkElements = 1000000
+
+// readBenchmarkIndex reads the given index into a new Stasher.
+func readBenchmarkIndex(b *testing.B, index []byte) Stasher {
+	stasher := NewStasher()
+	if err := Read(bytes.NewReader(index), stasher, nil, nil, nil); err != nil {
+		b.Fatalf("unexpected error reading index: %s", err)
+	}
+
+	return stasher
+}
+
+// heapInUse returns the number of bytes of live heap objects after a garbage collection.
+func heapInUse() uint64 {
+	runtime.GC()
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// BenchmarkStash measures the time to read an index of one million elements into a Stasher, as
+// well as the memory retained by the Stasher per element.
+func BenchmarkStash(b *testing.B) {
+	index := testindex.Valid(benchmarkElements)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var retained uint64
+	for i := 0; i < b.N; i++ {
+		before := heapInUse()
+		stasher := readBenchmarkIndex(b, index)
+		retained += heapInUse() - before
+		runtime.KeepAlive(stasher)
+	}
+
+	b.ReportMetric(float64(retained)/float64(b.N)/benchmarkElements, "retained-B/element")
+}
+
+// BenchmarkIterate measures the time to visit the label of every vertex and the adjacency of every
+// edge of a Stasher holding one million elements.
+func BenchmarkIterate(b *testing.B) {
+	stasher := readBenchmarkIndex(b, testindex.Valid(benchmarkElements))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		numRanges, numAdjacent := 0, 0
+		_ = stasher.VertexLabels(func(id int, label string) bool {
+			if label == "range" {
+				numRanges++
+			}
+			return true
+		})
+		_ = stasher.EdgeLabels(func(id int, label string, edge reader.Edge) bool {
+			numAdjacent += len(edge.InVs) + 1
+			return true
+		})
+
+		if numRanges == 0 || numAdjacent == 0 {
+			b.Fatalf("expected ranges and edges")
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+)
+
+var unmarshaller = jsoniter.ConfigFastest
+
+// interner converts raw LSIF identifiers into unique integer identifiers. See reader.Interner.
+type interner interface {
+	Intern(raw []byte) (int, error)
+}
+
+// unmarshalElement decodes a single line of LSIF into an element. The payloads of the decoded
+// elements match those produced by the lsif-protocol reader, except for the payloads of
+// hoverResult and packageInformation vertices (see HoverResult and PackageInformation). This
+// function also returns the header of the line, which holds the names of its top-level
+// properties.
+func unmarshalElement(interner interner, line []byte) (_ reader.Element, _ elementHeader, err error) {
+	header, err := readHeader(line)
+	if err != nil {
+		return reader.Element{}, elementHeader{}, err
+	}
+
+	id, err := internRaw(interner, header.id)
+	if err != nil {
+		return reader.Element{}, elementHeader{}, fmt.Errorf("illegal id: %v", err)
+	}
+
+	element := reader.Element{
+		ID:    id,
+		Type:  header.elementType,
+		Label: header.label,
+	}
+
+	if element.Type == "edge" {
+		element.Payload, err = unmarshalEdge(interner, line)
+	} else if element.Type == "vertex" {
+		if element.Label == "$event" {
+			element.Payload, err = unmarshalEvent(interner, line)
+		} else if unmarshaler, ok := vertexUnmarshalers[element.Label]; ok {
+			element.Payload, err = unmarshaler(line)
+		}
+	}
+
+	return element, header, err
+}
+
+// elementHeader holds the properties shared by every element, along with the names of the
+// top-level properties of the element and the value of its property field (which the
+// lsif-protocol reader does not decode for item edges).
+type elementHeader struct {
+	id          json.RawMessage
+	elementType string
+	label       string
+	names       []string
+	property    string
+}
+
+// readHeader decodes the header of the given line in a single pass over the line. The property
+// field of the header is empty unless the line has a property field whose value is a string.
+func readHeader(line []byte) (header elementHeader, _ error) {
+	iter := unmarshaller.BorrowIterator(line)
+	defer unmarshaller.ReturnIterator(iter)
+
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, name string) bool {
+		header.names = append(header.names, name)
+
+		switch name {
+		case "id":
+			header.id = iter.SkipAndReturnBytes()
+		case "type":
+			header.elementType = iter.ReadString()
+		case "label":
+			header.label = iter.ReadString()
+		case "property":
+			if iter.WhatIsNext() == jsoniter.StringValue {
+				header.property = iter.ReadString()
+			} else {
+				iter.Skip()
+			}
+		default:
+			iter.Skip()
+		}
+
+		return iter.Error == nil
+	})
+	if iter.Error != nil {
+		return elementHeader{}, iter.Error
+	}
+	if iter.WhatIsNext() != jsoniter.InvalidValue {
+		iter.ReportError("Unmarshal", "there are bytes left after unmarshal")
+		return elementHeader{}, iter.Error
+	}
+
+	return header, nil
+}
+
+func unmarshalEdge(interner interner, line []byte) (interface{}, error) {
+	var payload struct {
+		OutV     json.RawMessage   `json:"outV"`
+		InV      json.RawMessage   `json:"inV"`
+		InVs     []json.RawMessage `json:"inVs"`
+		Document json.RawMessage   `json:"document"`
+	}
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	outV, err := internRaw(interner, payload.OutV)
+	if err != nil {
+		return nil, fmt.Errorf("illegal outV: %v", err)
+	}
+	inV, err := internRaw(interner, payload.InV)
+	if err != nil {
+		return nil, fmt.Errorf("illegal inV: %v", err)
+	}
+	document, err := internRaw(interner, payload.Document)
+	if err != nil {
+		return nil, fmt.Errorf("illegal document: %v", err)
+	}
+
+	var inVs []int
+	for _, raw := range payload.InVs {
+		id, err := internRaw(interner, raw)
+		if err != nil {
+			return nil, fmt.Errorf("illegal inVs: %v", err)
+		}
+
+		inVs = append(inVs, id)
+	}
+
+	return reader.Edge{
+		OutV:     outV,
+		InV:      inV,
+		InVs:     inVs,
+		Document: document,
+	}, nil
+}
+
+// unmarshalEvent decodes the payload of a $event vertex. Unlike the payloads of other vertices,
+// this payload refers to another element, so its identifier is interned.
+func unmarshalEvent(interner interner, line []byte) (interface{}, error) {
+	var payload struct {
+		Kind  string          `json:"kind"`
+		Scope string          `json:"scope"`
+		Data  json.RawMessage `json:"data"`
+	}
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	data, err := internRaw(interner, payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("illegal data: %v", err)
+	}
+
+	return Event{
+		Kind:  payload.Kind,
+		Scope: payload.Scope,
+		Data:  data,
+	}, nil
+}
+
+var vertexUnmarshalers = map[string]func(line []byte) (interface{}, error){
+	"metaData":           unmarshalMetaData,
+	"project":            unmarshalProject,
+	"document":           unmarshalDocument,
+	"range":              unmarshalRange,
+	"hoverResult":        unmarshalHover,
+	"moniker":            unmarshalMoniker,
+	"packageInformation": unmarshalPackageInformation,
+	"diagnosticResult":   unmarshalDiagnosticResult,
+}
+
+func unmarshalMetaData(line []byte) (interface{}, error) {
+	var payload struct {
+		Version     string `json:"version"`
+		ProjectRoot string `json:"projectRoot"`
+	}
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	return reader.MetaData{
+		Version:     payload.Version,
+		ProjectRoot: payload.ProjectRoot,
+	}, nil
+}
+
+func unmarshalProject(line []byte) (interface{}, error) {
+	var payload struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	}
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	return Project{
+		Kind: payload.Kind,
+		Name: payload.Name,
+	}, nil
+}
+
+func unmarshalDocument(line []byte) (interface{}, error) {
+	var payload struct {
+		URI string `json:"uri"`
+	}
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	return payload.URI, nil
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type rangeBounds struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+func unmarshalRange(line []byte) (interface{}, error) {
+	var payload rangeBounds
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	return payload.toRange(), nil
+}
+
+// toRange converts the bounds into the payload of a range vertex.
+func (b rangeBounds) toRange() reader.Range {
+	return reader.Range{
+		StartLine:      b.Start.Line,
+		StartCharacter: b.Start.Character,
+		EndLine:        b.End.Line,
+		EndCharacter:   b.End.Character,
+	}
+}
+
+func unmarshalHover(line []byte) (interface{}, error) {
+	var payload struct {
+		Result struct {
+			Contents json.RawMessage `json:"contents"`
+			Range    *rangeBounds    `json:"range"`
+		} `json:"result"`
+	}
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	var hover HoverResult
+	if payload.Result.Range != nil {
+		r := payload.Result.Range.toRange()
+		hover.Range = &r
+	}
+
+	var target []json.RawMessage
+	if isNull(payload.Result.Contents) || unmarshaller.Unmarshal(payload.Result.Contents, &target) != nil {
+		v, part, err := unmarshalHoverPart(payload.Result.Contents)
+		if err != nil {
+			return nil, err
+		}
+
+		hover.Contents = string(v)
+		hover.Parts = []HoverPart{part}
+		return hover, nil
+	}
+
+	var parts [][]byte
+	for _, t := range target {
+		v, part, err := unmarshalHoverPart(t)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, v)
+		hover.Parts = append(hover.Parts, part)
+	}
+
+	hover.Contents = string(bytes.Join(parts, reader.HoverPartSeparator))
+	hover.IsArray = true
+	return hover, nil
+}
+
+// unmarshalHoverPart decodes a single MarkedString or MarkupContent of hover contents. This
+// function returns the text of the element as well as a description of its shape.
+func unmarshalHoverPart(raw json.RawMessage) ([]byte, HoverPart, error) {
+	if isNull(raw) {
+		return nil, HoverPart{Shape: HoverPartNull, Blank: true}, nil
+	}
+
+	var strPayload string
+	if err := unmarshaller.Unmarshal(raw, &strPayload); err == nil {
+		v := bytes.TrimSpace([]byte(strPayload))
+		return v, HoverPart{Shape: HoverPartString, Blank: len(v) == 0}, nil
+	}
+
+	var objPayload struct {
+		Kind     *string `json:"kind"`
+		Language string  `json:"language"`
+		Value    string  `json:"value"`
+	}
+	if err := unmarshaller.Unmarshal(raw, &objPayload); err != nil {
+		return nil, HoverPart{}, errors.New("unrecognized hover format")
+	}
+
+	part := HoverPart{Shape: HoverPartCodeBlock, Blank: len(bytes.TrimSpace([]byte(objPayload.Value))) == 0}
+	if objPayload.Kind != nil {
+		part.Shape = HoverPartMarkupContent
+		part.Kind = *objPayload.Kind
+	}
+
+	if len(objPayload.Language) > 0 {
+		v := make([]byte, 0, len(objPayload.Language)+len(objPayload.Value)+len(reader.CodeFence)*2+2)
+		v = append(v, reader.CodeFence...)
+		v = append(v, objPayload.Language...)
+		v = append(v, '\n')
+		v = append(v, objPayload.Value...)
+		v = append(v, '\n')
+		v = append(v, reader.CodeFence...)
+
+		return v, part, nil
+	}
+
+	return bytes.TrimSpace([]byte(objPayload.Value)), part, nil
+}
+
+// isNull returns true if the given raw message is the JSON literal null.
+func isNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+func unmarshalMoniker(line []byte) (interface{}, error) {
+	var payload struct {
+		Kind       string `json:"kind"`
+		Scheme     string `json:"scheme"`
+		Identifier string `json:"identifier"`
+	}
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	if payload.Kind == "" {
+		payload.Kind = "local"
+	}
+
+	return reader.Moniker{
+		Kind:       payload.Kind,
+		Scheme:     payload.Scheme,
+		Identifier: payload.Identifier,
+	}, nil
+}
+
+func unmarshalPackageInformation(line []byte) (interface{}, error) {
+	var payload struct {
+		Name    string `json:"name"`
+		Manager string `json:"manager"`
+		Version string `json:"version"`
+	}
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	return PackageInformation{
+		PackageInformation: reader.PackageInformation{
+			Name:    payload.Name,
+			Version: payload.Version,
+		},
+		Manager: payload.Manager,
+	}, nil
+}
+
+func unmarshalDiagnosticResult(line []byte) (interface{}, error) {
+	var payload struct {
+		Results []struct {
+			Severity int                `json:"severity"`
+			Code     reader.StringOrInt `json:"code"`
+			Message  string             `json:"message"`
+			Source   string             `json:"source"`
+			Range    rangeBounds        `json:"range"`
+		} `json:"result"`
+	}
+	if err := unmarshaller.Unmarshal(line, &payload); err != nil {
+		return nil, err
+	}
+
+	var diagnostics []reader.Diagnostic
+	for _, result := range payload.Results {
+		diagnostics = append(diagnostics, reader.Diagnostic{
+			Severity:       result.Severity,
+			Code:           string(result.Code),
+			Message:        result.Message,
+			Source:         result.Source,
+			StartLine:      result.Range.Start.Line,
+			StartCharacter: result.Range.Start.Character,
+			EndLine:        result.Range.End.Line,
+			EndCharacter:   result.Range.End.Character,
+		})
+	}
+
+	return diagnostics, nil
+}
+
+// internRaw trims whitespace from the raw message and submits it to the interner to produce a
+// unique identifier for this value.
+func internRaw(interner interner, raw json.RawMessage) (int, error) {
+	return interner.Intern(bytes.TrimSpace(raw))
+}
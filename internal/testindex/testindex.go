@@ -0,0 +1,117 @@
+// Package testindex generates synthetic LSIF indexes for tests and benchmarks.
+package testindex
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Emitter writes LSIF elements with sequential identifiers, one per line.
+type Emitter struct {
+	buf bytes.Buffer
+	id  int
+}
+
+// Emit writes an element whose fields after its identifier are given by the format string and
+// returns the identifier assigned to it. The format string must not contain the leading brace.
+func (e *Emitter) Emit(format string, args ...interface{}) int {
+	e.id++
+	fmt.Fprintf(&e.buf, `{"id":%d,`, e.id)
+	fmt.Fprintf(&e.buf, format, args...)
+	e.buf.WriteByte('\n')
+	return e.id
+}
+
+// NumElements returns the number of elements written so far.
+func (e *Emitter) NumElements() int {
+	return e.id
+}
+
+// Bytes returns the index written so far.
+func (e *Emitter) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// Join formats the given identifiers as the contents of an inVs array.
+func Join(ids []int) string {
+	var buf []byte
+	for i, id := range ids {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendInt(buf, int64(id), 10)
+	}
+
+	return string(buf)
+}
+
+// Valid returns a valid LSIF index with roughly the given number of elements. Each document
+// contains 50 ranges, each with a result set, hover result, and connecting edges.
+func Valid(numElements int) []byte {
+	var e Emitter
+	e.Emit(`"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}`)
+
+	for i := 0; e.NumElements() < numElements; i++ {
+		documentID := e.Emit(`"type":"vertex","label":"document","uri":"file:///project/%d.go"}`, i)
+
+		var rangeIDs []int
+		for j := 0; j < 50; j++ {
+			rangeID := e.Emit(`"type":"vertex","label":"range","start":{"line":%d,"character":1},"end":{"line":%d,"character":5}}`, j, j)
+			resultSetID := e.Emit(`"type":"vertex","label":"resultSet"}`)
+			e.Emit(`"type":"edge","label":"next","outV":%d,"inV":%d}`, rangeID, resultSetID)
+			hoverResultID := e.Emit(`"type":"vertex","label":"hoverResult","result":{"contents":{"language":"go","value":"func F%d()"}}}`, j)
+			e.Emit(`"type":"edge","label":"textDocument/hover","outV":%d,"inV":%d}`, resultSetID, hoverResultID)
+			rangeIDs = append(rangeIDs, rangeID)
+		}
+
+		e.Emit(`"type":"edge","label":"contains","outV":%d,"inVs":[%s]}`, documentID, Join(rangeIDs))
+	}
+
+	return e.Bytes()
+}
+
+// Invalid returns an LSIF index that violates a large number of rules. It contains enough
+// documents, ranges, and unreachable vertices that map iteration order would affect the
+// reported errors if it leaked into validation.
+func Invalid() []byte {
+	var e Emitter
+	e.Emit(`"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}`)
+
+	var documentIDs []int
+	for i := 0; i < 20; i++ {
+		documentIDs = append(documentIDs, e.Emit(`"type":"vertex","label":"document","uri":"file:///project/%d.go"}`, i))
+	}
+
+	for i, documentID := range documentIDs {
+		var rangeIDs []int
+		for j := 0; j < 10; j++ {
+			// Every range overlaps its neighbors
+			rangeIDs = append(rangeIDs, e.Emit(`"type":"vertex","label":"range","start":{"line":1,"character":%d},"end":{"line":1,"character":%d}}`, j, j+2))
+		}
+
+		// Illegal range extents
+		e.Emit(`"type":"vertex","label":"range","start":{"line":2,"character":5},"end":{"line":1,"character":0}}`)
+
+		inVs := Join(rangeIDs)
+		e.Emit(`"type":"edge","label":"contains","outV":%d,"inVs":[%s]}`, documentID, inVs)
+
+		// Claim the first range of the previous document
+		if i > 0 {
+			e.Emit(`"type":"edge","label":"contains","outV":%d,"inVs":[%d]}`, documentID, rangeIDs[0]-12)
+		}
+
+		// Refer to ranges of this document via the next document
+		resultID := e.Emit(`"type":"vertex","label":"definitionResult"}`)
+		e.Emit(`"type":"edge","label":"textDocument/definition","outV":%d,"inV":%d}`, rangeIDs[1], resultID)
+		e.Emit(`"type":"edge","label":"item","outV":%d,"inVs":[%s],"document":%d}`, resultID, inVs, documentIDs[(i+1)%len(documentIDs)])
+	}
+
+	for i := 0; i < 50; i++ {
+		e.Emit(`"type":"vertex","label":"resultSet"}`)
+		e.Emit(`"type":"vertex","label":"hoverResult","result":{"contents":"hover %d"}}`, i)
+		e.Emit(`"type":"vertex","label":"referenceResult"}`)
+	}
+
+	return e.Bytes()
+}
@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// DefaultMaxDetailedErrors is the default number of detailed errors that a single relationship
+// validator reports before summarizing the remaining violations.
+const DefaultMaxDetailedErrors = 100
+
+// errorAggregator records the violations found by a single relationship validator. The first
+// violations (up to the context's MaxDetailedErrors) are recorded as individual errors. If more
+// violations are found, a single summary error is recorded which counts all violations by kind.
+// Every recorded error is attributed to the rule checked by the validator, so that validators
+// can record errors concurrently.
+type errorAggregator struct {
+	ctx         *ValidationContext
+	ruleID      string
+	description string
+	count       int
+	countByKind map[string]int
+}
+
+// newErrorAggregator creates an errorAggregator for the given rule. The description is a plural
+// noun phrase that describes the violations in the summary error (e.g. "unreachable vertices").
+func newErrorAggregator(ctx *ValidationContext, ruleID, description string) *errorAggregator {
+	return &errorAggregator{
+		ctx:         ctx,
+		ruleID:      ruleID,
+		description: description,
+		countByKind: map[string]int{},
+	}
+}
+
+// add records a violation of the given kind. A detailed error with the given message and line
+// contexts is recorded unless the detail limit has been reached.
+func (a *errorAggregator) add(kind string, lineContexts []reader2.LineContext, message string, args ...interface{}) {
+	a.count++
+	a.countByKind[kind]++
+
+	if a.ctx.MaxDetailedErrors <= 0 || a.count <= a.ctx.MaxDetailedErrors {
+		a.ctx.AddError(message, args...).AddContext(lineContexts...).Rule = a.ruleID
+	}
+}
+
+// flush records the summary error if the number of violations exceeded the detail limit. This
+// method returns true if no violations were recorded.
+func (a *errorAggregator) flush() bool {
+	if a.ctx.MaxDetailedErrors > 0 && a.count > a.ctx.MaxDetailedErrors {
+		kinds := make([]string, 0, len(a.countByKind))
+		for kind := range a.countByKind {
+			kinds = append(kinds, kind)
+		}
+		sort.Slice(kinds, func(i, j int) bool {
+			ci, cj := a.countByKind[kinds[i]], a.countByKind[kinds[j]]
+			return ci > cj || (ci == cj && kinds[i] < kinds[j])
+		})
+
+		parts := make([]string, 0, len(kinds))
+		for _, kind := range kinds {
+			parts = append(parts, fmt.Sprintf("%s %s", formatCount(a.countByKind[kind]), kind))
+		}
+
+		a.ctx.AddError(
+			"%s %s: %s (only the first %s are listed)",
+			formatCount(a.count),
+			a.description,
+			strings.Join(parts, ", "),
+			formatCount(a.ctx.MaxDetailedErrors),
+		).Rule = a.ruleID
+	}
+
+	return a.count == 0
+}
+
+// formatCount formats the given non-negative integer with comma thousands separators.
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return s
+}
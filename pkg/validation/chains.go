@@ -0,0 +1,150 @@
+package validation
+
+import (
+	"sort"
+
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// chainResultLabels are the labels of the edges that each element of a result set chain may have
+// at most one of.
+var chainResultLabels = []string{"textDocument/definition", "textDocument/references", "textDocument/hover"}
+
+// resultSetChains indexes the next edges and the result edges out of each range and result set.
+// It is built once per validation and shared by the validators of result set chains.
+type resultSetChains struct {
+	nextEdges   map[int][]outEdge
+	resultEdges map[string]map[int][]outEdge
+
+	// order holds the identifier of each element of a chain. Elements without an incoming next
+	// edge come first, followed by the remaining elements, which sit on or behind a cycle.
+	order []int
+}
+
+// chainElement is an element of a result set chain visited by resultSetChains.walk.
+type chainElement struct {
+	id    int
+	label string
+
+	// path holds the line contexts of the chain from its first element up to this element.
+	path []reader2.LineContext
+
+	// next holds the next edges out of this element.
+	next []outEdge
+
+	// cycle holds the identifiers of the elements of the cycle closed by the single next edge
+	// out of this element, if it loops back on the chain.
+	cycle []int
+}
+
+// newResultSetChains uses the given context's Stasher to index the next edges and result edges
+// out of each range and result set.
+func newResultSetChains(ctx *ValidationContext) *resultSetChains {
+	c := &resultSetChains{
+		nextEdges:   outEdgesWithLabel(ctx, "next"),
+		resultEdges: make(map[string]map[int][]outEdge, len(chainResultLabels)),
+	}
+	for _, label := range chainResultLabels {
+		c.resultEdges[label] = outEdgesWithLabel(ctx, label)
+	}
+
+	// hasIncoming is a map from the identifier of each element of a chain to whether or not a
+	// next edge points to it
+	hasIncoming := map[int]bool{}
+	for outV, edges := range c.nextEdges {
+		if _, ok := hasIncoming[outV]; !ok {
+			hasIncoming[outV] = false
+		}
+		for _, edge := range edges {
+			hasIncoming[edge.inV] = true
+		}
+	}
+	for _, edgesByOutV := range c.resultEdges {
+		for outV := range edgesByOutV {
+			if _, ok := hasIncoming[outV]; !ok {
+				hasIncoming[outV] = false
+			}
+		}
+	}
+
+	var heads, rest []int
+	for id, incoming := range hasIncoming {
+		if incoming {
+			rest = append(rest, id)
+		} else {
+			heads = append(heads, id)
+		}
+	}
+	sort.Ints(heads)
+	sort.Ints(rest)
+	c.order = append(heads, rest...)
+
+	return c
+}
+
+// walk follows the next edges out of each range and result set and invokes the given function
+// with each element along the way. Each element is visited only by the first chain that reaches
+// it. A chain ends at an element with no next edge, with multiple next edges (as the rest of the
+// chain is ambiguous), or whose next edge closes a cycle.
+func (c *resultSetChains) walk(ctx *ValidationContext, f func(element chainElement)) {
+	visited := map[int]struct{}{}
+
+	for _, id := range c.order {
+		var path []reader2.LineContext
+		positions := map[int]int{}
+		var ids []int
+
+		for {
+			if _, ok := visited[id]; ok {
+				break
+			}
+			visited[id] = struct{}{}
+			positions[id] = len(ids)
+			ids = append(ids, id)
+			path = append(path, ctx.vertexContext(id))
+
+			label, ok := ctx.vertexLabel(id)
+			if !ok {
+				label = "element"
+			}
+
+			element := chainElement{id: id, label: label, path: path[:len(path):len(path)], next: c.nextEdges[id]}
+			if len(element.next) == 1 {
+				if position, ok := positions[element.next[0].inV]; ok {
+					element.cycle = ids[position:len(ids):len(ids)]
+				}
+			}
+			f(element)
+
+			if len(element.next) != 1 || element.cycle != nil {
+				break
+			}
+
+			path = append(path, ctx.edgeContext(element.next[0].id))
+			id = element.next[0].inV
+		}
+	}
+}
+
+// first returns the first edge with the given label out of an element of the chain starting at
+// the given range or result set. This function returns false if no element of the chain has
+// such an edge.
+func (c *resultSetChains) first(id int, label string) (outEdge, bool) {
+	visited := map[int]struct{}{}
+
+	for {
+		if _, ok := visited[id]; ok {
+			return outEdge{}, false
+		}
+		visited[id] = struct{}{}
+
+		if candidates := c.resultEdges[label][id]; len(candidates) > 0 {
+			return candidates[0], true
+		}
+		if len(c.nextEdges[id]) == 0 {
+			return outEdge{}, false
+		}
+
+		id = c.nextEdges[id][0].inV
+	}
+}
@@ -0,0 +1,360 @@
+package validation
+
+import (
+	"net/url"
+	"sort"
+	"sync"
+
+	protocol "github.com/sourcegraph/lsif-protocol/reader"
+	"github.com/sourcegraph/lsif-test/internal/reader"
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// ValidationContext holds shared state about the current validation.
+type ValidationContext struct {
+	Stasher reader.Stasher
+
+	Errors     []*reader.ValidationError
+	ErrorsLock sync.RWMutex
+
+	NumVertices uint64
+	NumEdges    uint64
+
+	// Severities is a map from rule identifiers to the severity of errors raised by that
+	// rule. Rules missing from this map have error severity.
+	Severities map[string]reader.Severity
+
+	// MaxErrors is the maximum number of errors returned by ReportedErrors (0 for no limit).
+	MaxErrors int
+
+	// MaxDetailedErrors is the maximum number of individual errors recorded by a single
+	// relationship validator before the remaining violations are summarized (0 for no limit).
+	MaxDetailedErrors int
+
+	// SourceRoot is a local directory containing the indexed sources. If non-empty, each
+	// document must refer to a file within this directory.
+	SourceRoot string
+
+	// ReachabilityIgnoreList is the set of vertex labels exempt from the reachability check.
+	ReachabilityIgnoreList []string
+
+	projectRoot     *url.URL
+	projectRootLock sync.RWMutex
+
+	invalidElements     map[int]struct{}
+	invalidElementsLock sync.RWMutex
+
+	graph     *reader.Graph
+	graphOnce sync.Once
+
+	ownershipMap map[int]OwnershipContext
+	once         sync.Once
+
+	projectOwnershipMap     map[int]OwnershipContext
+	projectOwnershipMapOnce sync.Once
+
+	resultSetChainsIndex     *resultSetChains
+	resultSetChainsIndexOnce sync.Once
+}
+
+// NewValidationContext create a new ValidationContext.
+func NewValidationContext() *ValidationContext {
+	return &ValidationContext{
+		Stasher:                reader.NewStasher(),
+		invalidElements:        map[int]struct{}{},
+		MaxDetailedErrors:      DefaultMaxDetailedErrors,
+		ReachabilityIgnoreList: DefaultReachabilityIgnoreList,
+	}
+}
+
+// AddError creates a new validaton error and saves it in the validation context.
+func (ctx *ValidationContext) AddError(message string, args ...interface{}) *reader2.ValidationError {
+	err := reader2.NewValidationError(message, args...)
+	ctx.addError(err)
+	return err
+}
+
+// addError saves the given validation error in the validation context.
+func (ctx *ValidationContext) addError(err *reader2.ValidationError) {
+	ctx.ErrorsLock.Lock()
+	ctx.Errors = append(ctx.Errors, err)
+	ctx.ErrorsLock.Unlock()
+}
+
+// ProjectRoot returns the project root declared by the metaData vertex, or nil if no valid
+// metaData vertex has been read.
+func (ctx *ValidationContext) ProjectRoot() *url.URL {
+	ctx.projectRootLock.RLock()
+	defer ctx.projectRootLock.RUnlock()
+
+	return ctx.projectRoot
+}
+
+// setProjectRoot records the project root declared by the metaData vertex.
+func (ctx *ValidationContext) setProjectRoot(projectRoot *url.URL) {
+	ctx.projectRootLock.Lock()
+	ctx.projectRoot = projectRoot
+	ctx.projectRootLock.Unlock()
+}
+
+// itemProperty returns the value of the property field of the item edge with the given
+// identifier from the context's Stasher, or an empty string if the edge has no such field.
+func (ctx *ValidationContext) itemProperty(id int) string {
+	lineContext, _ := ctx.Stasher.Edge(id)
+	return lineContext.ItemProperty
+}
+
+// Severity returns the configured severity of the given rule.
+func (ctx *ValidationContext) Severity(ruleID string) reader.Severity {
+	if severity, ok := ctx.Severities[ruleID]; ok {
+		return severity
+	}
+
+	return reader.SeverityError
+}
+
+// NumFailures returns the number of recorded errors with error severity.
+func (ctx *ValidationContext) NumFailures() int {
+	ctx.ErrorsLock.RLock()
+	defer ctx.ErrorsLock.RUnlock()
+
+	n := 0
+	for _, err := range ctx.Errors {
+		if err.Severity == reader.SeverityError {
+			n++
+		}
+	}
+
+	return n
+}
+
+// ReportedErrors returns the recorded errors, truncated to at most MaxErrors entries.
+func (ctx *ValidationContext) ReportedErrors() []*reader.ValidationError {
+	ctx.ErrorsLock.RLock()
+	defer ctx.ErrorsLock.RUnlock()
+
+	if ctx.MaxErrors > 0 && len(ctx.Errors) > ctx.MaxErrors {
+		return ctx.Errors[:ctx.MaxErrors]
+	}
+
+	return ctx.Errors
+}
+
+// tagErrors sets the given rule identifier on every error added to the context since the
+// given number of errors had been recorded. Errors already attributed to a rule are unchanged.
+// Each error then takes the configured severity of its rule, and errors of disabled rules are
+// discarded. An empty rule identifier only applies the severities of errors already attributed
+// to a rule. This method returns the number of retained errors with error severity.
+func (ctx *ValidationContext) tagErrors(offset int, ruleID string) int {
+	ctx.ErrorsLock.Lock()
+	defer ctx.ErrorsLock.Unlock()
+
+	numFailures := 0
+	errs := ctx.Errors[:offset]
+	for _, err := range ctx.Errors[offset:] {
+		if err.Rule == "" {
+			err.Rule = ruleID
+		}
+
+		if err.Severity = ctx.Severity(err.Rule); err.Severity != reader.SeverityOff {
+			errs = append(errs, err)
+		}
+		if err.Severity == reader.SeverityError {
+			numFailures++
+		}
+	}
+
+	ctx.Errors = errs
+	return numFailures
+}
+
+// sortErrors orders the recorded errors by rule identifier and then by the line indexes of
+// their relevant lines. Errors without relevant lines (such as summaries) are ordered after
+// the other errors of the same rule.
+func (ctx *ValidationContext) sortErrors() {
+	ctx.ErrorsLock.Lock()
+	defer ctx.ErrorsLock.Unlock()
+
+	sort.SliceStable(ctx.Errors, func(i, j int) bool {
+		e1, e2 := ctx.Errors[i], ctx.Errors[j]
+		if e1.Rule != e2.Rule {
+			return e1.Rule < e2.Rule
+		}
+
+		for k := 0; k < len(e1.RelevantLines) && k < len(e2.RelevantLines); k++ {
+			if e1.RelevantLines[k].Index != e2.RelevantLines[k].Index {
+				return e1.RelevantLines[k].Index < e2.RelevantLines[k].Index
+			}
+		}
+
+		if len(e1.RelevantLines) == 0 || len(e2.RelevantLines) == 0 {
+			return len(e1.RelevantLines) > len(e2.RelevantLines)
+		}
+
+		return len(e1.RelevantLines) < len(e2.RelevantLines)
+	})
+}
+
+// markInvalid excludes the element with the given identifier from relationship validation.
+func (ctx *ValidationContext) markInvalid(id int) {
+	ctx.invalidElementsLock.Lock()
+	ctx.invalidElements[id] = struct{}{}
+	ctx.invalidElementsLock.Unlock()
+}
+
+// isValid returns false if the element with the given identifier failed validation and should
+// be excluded from relationship validation.
+func (ctx *ValidationContext) isValid(id int) bool {
+	ctx.invalidElementsLock.RLock()
+	_, ok := ctx.invalidElements[id]
+	ctx.invalidElementsLock.RUnlock()
+
+	return !ok
+}
+
+// vertexLabels invokes the given function with the identifier and label of each vertex of the
+// context's Stasher which has not been marked invalid. See Stasher.VertexLabels.
+func (ctx *ValidationContext) vertexLabels(f func(id int, label string) bool) bool {
+	return ctx.Stasher.VertexLabels(func(id int, label string) bool {
+		return !ctx.isValid(id) || f(id, label)
+	})
+}
+
+// edgesWithLabel invokes the given function with the identifier and adjacent vertices of each edge
+// with the given label which has not been marked invalid. See Graph.EdgesWithLabel.
+func (ctx *ValidationContext) edgesWithLabel(label string, f func(id int, edge protocol.Edge) bool) bool {
+	graph := ctx.Graph()
+	for _, id := range graph.EdgesWithLabel(label) {
+		if !ctx.isValid(id) {
+			continue
+		}
+
+		if edge, ok := graph.Edge(id); ok && !f(id, edge) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verticesWithLabel returns the identifiers of the vertices with the given label which have not
+// been marked invalid. See Graph.VerticesWithLabel.
+func (ctx *ValidationContext) verticesWithLabel(label string) []int {
+	var ids []int
+	for _, id := range ctx.Graph().VerticesWithLabel(label) {
+		if ctx.isValid(id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// outNeighbors returns the identifiers of the vertices adjacent to the out edges of the given
+// vertex, excluding edges which have been marked invalid. See Graph.OutNeighbors.
+func (ctx *ValidationContext) outNeighbors(id int) []int {
+	graph := ctx.Graph()
+
+	var neighbors []int
+	for _, edgeID := range graph.OutEdges(id) {
+		if !ctx.isValid(edgeID) {
+			continue
+		}
+
+		if edge, ok := graph.Edge(edgeID); ok {
+			neighbors = append(neighbors, reader.EachInV(edge)...)
+		}
+	}
+
+	return neighbors
+}
+
+// vertexLabel returns the label of the vertex with the given identifier from the context's
+// Stasher, unless it has been marked invalid.
+func (ctx *ValidationContext) vertexLabel(id int) (string, bool) {
+	if !ctx.isValid(id) {
+		return "", false
+	}
+
+	return ctx.Stasher.VertexLabel(id)
+}
+
+// rangeBounds returns the bounds of the range vertex with the given identifier from the context's
+// Stasher, unless it has been marked invalid.
+func (ctx *ValidationContext) rangeBounds(id int) (protocol.Range, bool) {
+	if !ctx.isValid(id) {
+		return protocol.Range{}, false
+	}
+
+	return ctx.Stasher.Range(id)
+}
+
+// vertexContext returns the vertex with the given identifier from the context's Stasher. This
+// is used to attach the vertex to an error, and returns only the identifier if the vertex is
+// unknown.
+func (ctx *ValidationContext) vertexContext(id int) reader.LineContext {
+	if lineContext, ok := ctx.Stasher.Vertex(id); ok {
+		return lineContext
+	}
+
+	return reader.LineContext{Element: protocol.Element{ID: id}}
+}
+
+// edgeContext returns the edge with the given identifier from the context's Stasher. This is
+// used to attach the edge to an error, and returns only the identifier if the edge is unknown.
+func (ctx *ValidationContext) edgeContext(id int) reader.LineContext {
+	if lineContext, ok := ctx.Stasher.Edge(id); ok {
+		return lineContext
+	}
+
+	return reader.LineContext{Element: protocol.Element{ID: id}}
+}
+
+// numErrors returns the number of errors recorded so far.
+func (ctx *ValidationContext) numErrors() int {
+	ctx.ErrorsLock.RLock()
+	defer ctx.ErrorsLock.RUnlock()
+
+	return len(ctx.Errors)
+}
+
+// Graph returns the graph index of the context's Stasher. One will be created from the current
+// state of the context's Stasher if one does not yet exist.
+func (ctx *ValidationContext) Graph() *reader.Graph {
+	ctx.graphOnce.Do(func() {
+		ctx.graph = reader.NewGraph(ctx.Stasher)
+	})
+
+	return ctx.graph
+}
+
+// OwnershipMap returns the context's ownership map. One will be created from the
+// current state of the context's Stasher if one does not yet exist.
+func (ctx *ValidationContext) OwnershipMap() map[int]OwnershipContext {
+	ctx.once.Do(func() {
+		ctx.ownershipMap = ownershipMap(ctx)
+	})
+
+	return ctx.ownershipMap
+}
+
+// ProjectOwnershipMap returns the context's project ownership map, which maps each document
+// contained by a project to that project. One will be created from the current state of the
+// context's Stasher if one does not yet exist.
+func (ctx *ValidationContext) ProjectOwnershipMap() map[int]OwnershipContext {
+	ctx.projectOwnershipMapOnce.Do(func() {
+		ctx.projectOwnershipMap = projectOwnershipMap(ctx)
+	})
+
+	return ctx.projectOwnershipMap
+}
+
+// resultSetChains returns the index of the context's result set chains. One will be created from
+// the current state of the context's Stasher if one does not yet exist.
+func (ctx *ValidationContext) resultSetChains() *resultSetChains {
+	ctx.resultSetChainsIndexOnce.Do(func() {
+		ctx.resultSetChainsIndex = newResultSetChains(ctx)
+	})
+
+	return ctx.resultSetChainsIndex
+}
@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// fingerprinter computes identifiers of validation errors that are independent of element
+// identifiers and line numbers, so that the same problem yields the same fingerprint after
+// the index is regenerated.
+type fingerprinter struct {
+	ctx          *ValidationContext
+	documentURIs map[int]string
+}
+
+// newFingerprinter creates a fingerprinter for errors raised within the given context.
+func newFingerprinter(ctx *ValidationContext) *fingerprinter {
+	return &fingerprinter{ctx: ctx, documentURIs: documentURIs(ctx)}
+}
+
+// fingerprint returns the fingerprint of the given error. The fingerprint covers the rule of the
+// error, the label and identifier-free payload of each relevant line (or its text without element
+// identifiers, if the line could not be parsed), and the URIs of the documents that the relevant lines belong to.
+func (f *fingerprinter) fingerprint(err *reader2.ValidationError) string {
+	parts := []string{err.Rule}
+	uris := map[string]struct{}{}
+
+	for _, lineContext := range err.RelevantLines {
+		parts = append(parts, lineContext.Element.Type, lineContext.Element.Label, f.payload(lineContext), stripIdentifiers(lineContext.Raw))
+
+		if uri, ok := f.documentURIs[lineContext.Element.ID]; ok {
+			uris[uri] = struct{}{}
+		}
+		if edge, ok := lineContext.Element.Payload.(reader.Edge); ok {
+			if uri, ok := f.documentURIs[edge.Document]; ok {
+				uris[uri] = struct{}{}
+			}
+		}
+	}
+
+	sortedURIs := make([]string, 0, len(uris))
+	for uri := range uris {
+		sortedURIs = append(sortedURIs, uri)
+	}
+	sort.Strings(sortedURIs)
+
+	sum := sha256.Sum256([]byte(strings.Join(append(parts, sortedURIs...), "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// payload returns a serialized form of the payload of the given element that does not refer to
+// any element identifiers. Edges are described by the labels of their adjacent vertices.
+func (f *fingerprinter) payload(lineContext reader2.LineContext) string {
+	if edge, ok := lineContext.Element.Payload.(reader.Edge); ok {
+		var inLabels []string
+		for _, inV := range reader2.EachInV(edge) {
+			inLabels = append(inLabels, f.label(inV))
+		}
+		sort.Strings(inLabels)
+
+		return f.label(edge.OutV) + "->" + strings.Join(inLabels, ",")
+	}
+
+	serialized, err := json.Marshal(lineContext.Element.Payload)
+	if err != nil {
+		return ""
+	}
+
+	return string(serialized)
+}
+
+// identifierProperties matches the properties of a (possibly malformed) line that refer to
+// element identifiers, along with their values. A trailing array may be unterminated.
+var identifierProperties = regexp.MustCompile(`"(id|outV|inV|inVs|document|data)"\s*:\s*(\[[^\]]*\]?|"(?:[^"\\]|\\.)*"|[-+.0-9eE]+)`)
+
+// stripIdentifiers removes the values of the properties that refer to element identifiers from
+// the given text of a line that could not be parsed.
+func stripIdentifiers(raw string) string {
+	return identifierProperties.ReplaceAllString(raw, `"$1":_`)
+}
+
+// label returns the label of the vertex with the given identifier.
+func (f *fingerprinter) label(id int) string {
+	label, _ := f.ctx.Stasher.VertexLabel(id)
+	return label
+}
+
+// documentURIs returns a map from document and range identifiers to the URI of the document
+// they belong to. Unlike the ownership map, this raises no errors for ranges claimed by multiple
+// documents.
+func documentURIs(ctx *ValidationContext) map[int]string {
+	uris := map[int]string{}
+	graph := ctx.Graph()
+	for _, id := range graph.VerticesWithLabel("document") {
+		if lineContext, ok := ctx.Stasher.Vertex(id); ok {
+			if uri, ok := lineContext.Element.Payload.(string); ok {
+				uris[id] = uri
+			}
+		}
+	}
+
+	for _, id := range graph.EdgesWithLabel("contains") {
+		edge, _ := graph.Edge(id)
+		if uri, ok := uris[edge.OutV]; ok {
+			for _, inV := range reader2.EachInV(edge) {
+				if _, ok := uris[inV]; !ok {
+					uris[inV] = uri
+				}
+			}
+		}
+	}
+
+	return uris
+}
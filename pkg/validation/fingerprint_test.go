@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMalformedLineFingerprints(t *testing.T) {
+	fingerprint := func(index string) string {
+		report, err := Validate(strings.NewReader(index), Options{})
+		if err != nil {
+			t.Fatalf("unexpected error validating index: %s", err)
+		}
+
+		for _, err := range report.Errors {
+			if err.Rule == RuleMalformedLine {
+				return report.Fingerprint(err)
+			}
+		}
+
+		t.Fatalf("expected malformed line error")
+		return ""
+	}
+
+	original := fingerprint(exampleMetaData + `
+{"id":2,"type":"edge","label":"next","outV":3,"inVs":[4,5`)
+	regenerated := fingerprint(exampleMetaData + `
+{"id":"a","type":"vertex","label":"document","uri":"file:///project/main.go"}
+
+{"id":12,"type":"edge","label":"next","outV":"b","inVs":[14,15`)
+	different := fingerprint(exampleMetaData + `
+{"id":2,"type":"edge","label":"item","outV":3,"inVs":[4,5`)
+
+	if original != regenerated {
+		t.Errorf("expected fingerprints to be independent of identifiers and line numbers")
+	}
+	if original == different {
+		t.Errorf("expected fingerprints of different lines to differ")
+	}
+}
+
+func TestEventFingerprints(t *testing.T) {
+	fingerprint := func(index string) string {
+		report, err := Validate(strings.NewReader(index), Options{})
+		if err != nil {
+			t.Fatalf("unexpected error validating index: %s", err)
+		}
+
+		for _, err := range report.Errors {
+			if err.Rule == RuleEventBracketing {
+				return report.Fingerprint(err)
+			}
+		}
+
+		t.Fatalf("expected event bracketing error:\n%s", formatErrors(report))
+		return ""
+	}
+
+	original := fingerprint(exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":2}`)
+	renumbered := fingerprint(exampleMetaData + `
+{"id":7,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":8,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":7}`)
+
+	if original != renumbered {
+		t.Errorf("expected fingerprints to be independent of identifiers")
+	}
+}
@@ -0,0 +1,173 @@
+// Command gendocs generates the Go source of the default schema from schema.yaml, and the table
+// of edge labels in the README from the same schema. It is run by go generate from the directory
+// of the validation package.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/sourcegraph/lsif-test/pkg/validation"
+)
+
+const (
+	schemaFile       = "schema.yaml"
+	schemaSourceFile = "schema_default.go"
+	readmeFile       = "../../README.md"
+
+	beginMarker = "<!-- schema:begin (generated from pkg/validation/schema.yaml by go generate) -->"
+	endMarker   = "<!-- schema:end -->"
+)
+
+func main() {
+	contents, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	schema, err := validation.ParseSchema(contents)
+	if err != nil {
+		log.Fatalf("malformed schema: %s", err)
+	}
+
+	if err := writeSchemaSource(contents); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := writeReadme(schema); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeSchemaSource writes a Go source file declaring the given schema as a string constant.
+func writeSchemaSource(contents []byte) error {
+	source := fmt.Sprintf(`// Code generated by gendocs from %s. DO NOT EDIT.
+
+package validation
+
+// DefaultSchemaYAML is the YAML representation of the default schema.
+const DefaultSchemaYAML = %s
+`, schemaFile, "`"+strings.Replace(string(contents), "`", "`+\"`\"+`", -1)+"`")
+
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(schemaSourceFile, formatted, 0644)
+}
+
+// writeReadme replaces the generated section of the README with a description of the given
+// schema.
+func writeReadme(schema *validation.Schema) error {
+	contents, err := ioutil.ReadFile(readmeFile)
+	if err != nil {
+		return err
+	}
+
+	begin := bytes.Index(contents, []byte(beginMarker))
+	end := bytes.Index(contents, []byte(endMarker))
+	if begin < 0 || end < begin {
+		return fmt.Errorf("%s has no generated schema section", readmeFile)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(contents[:begin+len(beginMarker)])
+	buf.WriteString("\n")
+	buf.WriteString(describeSchema(schema))
+	buf.Write(contents[end:])
+
+	return ioutil.WriteFile(readmeFile, buf.Bytes(), 0644)
+}
+
+// describeSchema returns the markdown list items describing the given schema, indented to match
+// the surrounding list of the README.
+func describeSchema(schema *validation.Schema) string {
+	var buf bytes.Buffer
+
+	var required []string
+	for _, vertex := range schema.Vertices {
+		if len(vertex.Properties) > 0 {
+			required = append(required, fmt.Sprintf("`%s` (%s)", vertex.Label, formatLabels(vertex.Properties, ", ")))
+		}
+	}
+	if len(required) > 0 {
+		fmt.Fprintf(&buf, "- Vertices have the properties required for their label: %s\n", strings.Join(required, ", "))
+	}
+
+	rows := [][]string{{"label", "inV(s)", "outV", "condition", "required properties"}}
+	conditional := map[string]bool{}
+	for _, edge := range schema.Edges {
+		condition := ""
+		if edge.When != nil {
+			condition = "if outV is " + article(edge.When.OutV[0]) + " " + formatLabels(edge.When.OutV, "/")
+			conditional[edge.Label] = true
+		} else if conditional[edge.Label] {
+			condition = "otherwise"
+		}
+
+		rows = append(rows, []string{
+			"`" + edge.Label + "`",
+			formatLabels(edge.InV, "/"),
+			formatLabels(edge.OutV, "/"),
+			condition,
+			formatLabels(edge.Properties, ", "),
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	fmt.Fprintf(&buf, "- Edges refer to identifiers attached to the correct element type, as follows:\n\n")
+	for i, row := range rows {
+		writeRow(&buf, row, widths)
+		if i == 0 {
+			separator := make([]string, len(widths))
+			for j, width := range widths {
+				separator[j] = strings.Repeat("-", width)
+			}
+			writeRow(&buf, separator, widths)
+		}
+	}
+	buf.WriteString("\n")
+
+	return buf.String()
+}
+
+// writeRow writes an indented markdown table row with cells padded to the given widths.
+func writeRow(buf *bytes.Buffer, cells []string, widths []int) {
+	buf.WriteString("   ")
+	for i, cell := range cells {
+		fmt.Fprintf(buf, " | %-*s", widths[i], cell)
+	}
+	buf.WriteString(" |\n")
+}
+
+// formatLabels formats each of the given labels as code, joined by the given separator.
+func formatLabels(labels []string, separator string) string {
+	formatted := make([]string, 0, len(labels))
+	for _, label := range labels {
+		formatted = append(formatted, "`"+label+"`")
+	}
+
+	return strings.Join(formatted, separator)
+}
+
+// article returns the indefinite article that precedes the given label.
+func article(label string) string {
+	if strings.ContainsAny(label[:1], "aeiou") {
+		return "an"
+	}
+
+	return "a"
+}
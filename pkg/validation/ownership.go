@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"sort"
+
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// OwnershipContext bundles the identifier of a vertex that owns another vertex (a document that
+// owns a range, or a project that owns a document) and the identifier of a contains edge that
+// refers to the owner via its OutV property.
+type OwnershipContext struct {
+	OwnerID int
+	EdgeID  int
+}
+
+// ownershipMap uses the given context's Stasher to create a mapping from range identifiers
+// to an OwnershipContext value, which bundles a document identifier as well as the identifier
+// of the edge that ties them together. An error is marked for every range claimed by more
+// than one document. If the unique range ownership rule has error severity, such ranges are
+// omitted from the map and excluded from the remainder of relationship validation; otherwise
+// they are mapped to the document whose contains edge was registered first.
+func ownershipMap(ctx *ValidationContext) map[int]OwnershipContext {
+	ownershipMap := map[int]OwnershipContext{}
+	conflicts := map[int]struct{}{}
+
+	_ = ctx.edgesWithLabel("contains", func(id int, edge reader.Edge) bool {
+		if outLabel, ok := ctx.vertexLabel(edge.OutV); !ok || outLabel != "document" {
+			return true
+		}
+
+		return reader2.ForEachInV(edge, func(inV int) bool {
+			if other, ok := ownershipMap[inV]; ok {
+				ctx.AddError("range %d already claimed by document %d", inV, other.OwnerID).AddContext(ctx.edgeContext(id), ctx.edgeContext(other.EdgeID)).Rule = RuleUniqueRangeOwnership
+				conflicts[inV] = struct{}{}
+				return true
+			}
+
+			ownershipMap[inV] = OwnershipContext{OwnerID: edge.OutV, EdgeID: id}
+			return true
+		})
+	})
+
+	if ctx.Severity(RuleUniqueRangeOwnership) == reader2.SeverityError {
+		for rangeID := range conflicts {
+			delete(ownershipMap, rangeID)
+			ctx.markInvalid(rangeID)
+		}
+	}
+
+	return ownershipMap
+}
+
+// documentRanges returns the sorted identifiers of the ranges that the given ownership map assigns
+// to the given document.
+func documentRanges(ctx *ValidationContext, ownershipMap map[int]OwnershipContext, documentID int) []int {
+	var rangeIDs []int
+	for _, id := range ctx.Graph().OutNeighbors(documentID) {
+		if ownershipContext, ok := ownershipMap[id]; ok && ownershipContext.OwnerID == documentID {
+			rangeIDs = append(rangeIDs, id)
+		}
+	}
+	sort.Ints(rangeIDs)
+
+	// A document may refer to the same range via multiple edges
+	deduplicated := rangeIDs[:0]
+	for i, id := range rangeIDs {
+		if i == 0 || id != rangeIDs[i-1] {
+			deduplicated = append(deduplicated, id)
+		}
+	}
+
+	return deduplicated
+}
+
+// projectOwnershipMap uses the given context's Stasher to create a mapping from document
+// identifiers to an OwnershipContext value, which bundles the identifier of the project that
+// contains the document as well as the identifier of the edge that ties them together. A
+// document contained by multiple projects is mapped to the project whose contains edge was
+// registered first (see ensureUniqueDocumentOwnership).
+func projectOwnershipMap(ctx *ValidationContext) map[int]OwnershipContext {
+	ownershipMap := map[int]OwnershipContext{}
+
+	_ = ctx.edgesWithLabel("contains", func(id int, edge reader.Edge) bool {
+		if outLabel, ok := ctx.vertexLabel(edge.OutV); !ok || outLabel != "project" {
+			return true
+		}
+
+		return reader2.ForEachInV(edge, func(inV int) bool {
+			if _, ok := ownershipMap[inV]; !ok {
+				ownershipMap[inV] = OwnershipContext{OwnerID: edge.OutV, EdgeID: id}
+			}
+
+			return true
+		})
+	})
+
+	return ownershipMap
+}
@@ -0,0 +1,193 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Registry is a set of rules and the validators that check them, along with the schema of vertex
+// and edge labels. A registry created by NewRegistry holds every built-in rule and validator and
+// the default schema; custom rules and validators can be registered alongside them, and the schema
+// can be replaced. Errors raised by custom validators are attributed to their rule and take the
+// severity configured for it, exactly like the errors of built-in validators.
+//
+// A registry must not be modified while it is used by a running validation.
+type Registry struct {
+	rules                  map[string]Rule
+	schema                 *Schema
+	schemaVertexValidators map[string][]elementRule
+	schemaEdgeValidators   map[string][]elementRule
+	unknownVertexLabel     []elementRule
+	unknownEdgeLabel       []elementRule
+	vertexValidators       map[string][]elementRule
+	edgeValidators         map[string][]elementRule
+	relationshipValidators []relationshipRule
+}
+
+// defaultRegistry holds the built-in rules and validators. It is used when no registry is supplied.
+var defaultRegistry = NewRegistry()
+
+// NewRegistry creates a registry holding the built-in rules and validators and the default schema.
+func NewRegistry() *Registry {
+	r := &Registry{
+		rules:            map[string]Rule{},
+		vertexValidators: map[string][]elementRule{},
+		edgeValidators:   map[string][]elementRule{},
+	}
+
+	for id, rule := range rules {
+		r.rules[id] = rule
+	}
+	for label, rules := range vertexValidators {
+		r.vertexValidators[label] = append([]elementRule(nil), rules...)
+	}
+	for _, rule := range relationshipValidators {
+		// The built-in relationship validators attribute their own errors to their rule
+		rule.concurrent = true
+		r.relationshipValidators = append(r.relationshipValidators, rule)
+	}
+
+	if err := r.SetSchema(DefaultSchema()); err != nil {
+		panic(fmt.Sprintf("invalid default schema: %s", err))
+	}
+
+	return r
+}
+
+// SetSchema replaces the schema checked by the registry. Each rule named by the schema must be
+// registered.
+func (r *Registry) SetSchema(schema *Schema) error {
+	vertexValidators, edgeValidators, err := compileSchema(schema, r.LookupRule)
+	if err != nil {
+		return err
+	}
+
+	r.schema = schema
+	r.schemaVertexValidators = vertexValidators
+	r.schemaEdgeValidators = edgeValidators
+	r.unknownVertexLabel = []elementRule{{RuleUnknownLabel, makeUnknownLabelValidator(vertexValidators)}}
+	r.unknownEdgeLabel = []elementRule{{RuleUnknownLabel, makeUnknownLabelValidator(edgeValidators)}}
+	return nil
+}
+
+// schemaVertexRules returns the element rules that check vertices with the given label against the
+// schema. If the schema does not declare the label, the vertex is reported as unknown.
+func (r *Registry) schemaVertexRules(label string) []elementRule {
+	if rules, ok := r.schemaVertexValidators[label]; ok {
+		return rules
+	}
+
+	return r.unknownVertexLabel
+}
+
+// schemaEdgeRules returns the element rules that check edges with the given label against the
+// schema. If the schema does not declare the label, the edge is reported as unknown.
+func (r *Registry) schemaEdgeRules(label string) []elementRule {
+	if rules, ok := r.schemaEdgeValidators[label]; ok {
+		return rules
+	}
+
+	return r.unknownEdgeLabel
+}
+
+// Schema returns the schema checked by the registry.
+func (r *Registry) Schema() *Schema {
+	return r.schema
+}
+
+// RegisterRule adds a rule to the registry. The identifier and name of the rule must not be empty,
+// and must not be the identifier or name of another rule.
+func (r *Registry) RegisterRule(rule Rule) error {
+	if rule.ID == "" || rule.Name == "" {
+		return fmt.Errorf("rule must have an identifier and a name")
+	}
+
+	for _, other := range r.rules {
+		if rule.ID == other.ID || rule.ID == other.Name || rule.Name == other.ID || rule.Name == other.Name {
+			return fmt.Errorf("rule %s (%s) conflicts with rule %s (%s)", rule.ID, rule.Name, other.ID, other.Name)
+		}
+	}
+
+	r.rules[rule.ID] = rule
+	return nil
+}
+
+// RegisterVertexValidator adds a validator that is invoked on each vertex with the given label. The
+// validator is invoked after the schema and the validators already registered for the label have
+// been checked, and errors it raises are attributed to the given rule, which must already be
+// registered. If the validator raises errors with error severity, the vertex is excluded from
+// relationship validation.
+func (r *Registry) RegisterVertexValidator(label, ruleID string, validator ElementValidator) error {
+	if _, ok := r.rules[ruleID]; !ok {
+		return fmt.Errorf("unknown rule %s", ruleID)
+	}
+
+	r.vertexValidators[label] = append(r.vertexValidators[label], elementRule{ruleID, validator})
+	return nil
+}
+
+// RegisterEdgeValidator adds a validator that is invoked on each edge with the given label. See
+// RegisterVertexValidator.
+func (r *Registry) RegisterEdgeValidator(label, ruleID string, validator ElementValidator) error {
+	if _, ok := r.rules[ruleID]; !ok {
+		return fmt.Errorf("unknown rule %s", ruleID)
+	}
+
+	r.edgeValidators[label] = append(r.edgeValidators[label], elementRule{ruleID, validator})
+	return nil
+}
+
+// RegisterRelationshipValidator adds a validator that is invoked once every element of the index
+// has been read. Errors it raises are attributed to the given rule, which must already be
+// registered. Custom relationship validators are invoked one at a time, in registration order,
+// after the built-in relationship validators have completed.
+func (r *Registry) RegisterRelationshipValidator(ruleID string, validator RelationshipValidator) error {
+	if _, ok := r.rules[ruleID]; !ok {
+		return fmt.Errorf("unknown rule %s", ruleID)
+	}
+
+	r.relationshipValidators = append(r.relationshipValidators, relationshipRule{RuleID: ruleID, Validator: validator})
+	return nil
+}
+
+// Rules returns every rule of the registry ordered by identifier.
+func (r *Registry) Rules() []Rule {
+	all := make([]Rule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		all = append(all, rule)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	return all
+}
+
+// severities returns a map from rule identifiers to the severity of that rule, which is the
+// severity in the given map, or the default severity of the rule if it is not in the map.
+func (r *Registry) severities(configured map[string]Severity) map[string]Severity {
+	severities := map[string]Severity{}
+	for id, rule := range r.rules {
+		if rule.DefaultSeverity != "" {
+			severities[id] = rule.DefaultSeverity
+		}
+	}
+	for id, severity := range configured {
+		severities[id] = severity
+	}
+
+	return severities
+}
+
+// LookupRule returns the rule of the registry with the given identifier or name.
+func (r *Registry) LookupRule(idOrName string) (Rule, bool) {
+	if rule, ok := r.rules[idOrName]; ok {
+		return rule, true
+	}
+
+	for _, rule := range r.rules {
+		if rule.Name == idOrName {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
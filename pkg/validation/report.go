@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"sync/atomic"
+
+	reader "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// Error is an error found in an LSIF index, along with the lines of the index relevant to it.
+type Error = reader.ValidationError
+
+// LineContext holds a line index of an LSIF index and the element parsed from that line.
+type LineContext = reader.LineContext
+
+// Severity describes how an error affects the outcome of a validation.
+type Severity = reader.Severity
+
+const (
+	// SeverityError marks errors that fail the validation.
+	SeverityError = reader.SeverityError
+	// SeverityWarning marks errors that are reported but do not fail the validation.
+	SeverityWarning = reader.SeverityWarning
+	// SeverityOff marks rules that are not checked.
+	SeverityOff = reader.SeverityOff
+)
+
+// ParseSeverity converts the given string into a severity.
+func ParseSeverity(value string) (Severity, error) {
+	return reader.ParseSeverity(value)
+}
+
+// Report is the result of a validation.
+type Report struct {
+	// Errors holds every error and warning found in the index, ordered by rule identifier and
+	// then by line.
+	Errors []*Error
+
+	// NumVertices and NumEdges are the number of vertices and edges read from the index.
+	NumVertices uint64
+	NumEdges    uint64
+
+	maxErrors    int
+	fingerprints map[*Error]string
+}
+
+// newReport creates a report of the completed validation with the given context. The errors
+// are fingerprinted eagerly, as the context's Stasher is closed once validation completes.
+func newReport(ctx *ValidationContext) Report {
+	fingerprinter := newFingerprinter(ctx)
+	fingerprints := make(map[*Error]string, len(ctx.Errors))
+	for _, err := range ctx.Errors {
+		fingerprints[err] = fingerprinter.fingerprint(err)
+	}
+
+	return Report{
+		Errors:       ctx.Errors,
+		NumVertices:  atomic.LoadUint64(&ctx.NumVertices),
+		NumEdges:     atomic.LoadUint64(&ctx.NumEdges),
+		maxErrors:    ctx.MaxErrors,
+		fingerprints: fingerprints,
+	}
+}
+
+// NumFailures returns the number of errors with error severity.
+func (r Report) NumFailures() int {
+	n := 0
+	for _, err := range r.Errors {
+		if err.Severity == SeverityError {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Failures returns the errors with error severity.
+func (r Report) Failures() []*Error {
+	var failures []*Error
+	for _, err := range r.Errors {
+		if err.Severity == SeverityError {
+			failures = append(failures, err)
+		}
+	}
+
+	return failures
+}
+
+// ReportedErrors returns the errors of the report, truncated to at most MaxErrors entries.
+func (r Report) ReportedErrors() []*Error {
+	if r.maxErrors > 0 && len(r.Errors) > r.maxErrors {
+		return r.Errors[:r.maxErrors]
+	}
+
+	return r.Errors
+}
+
+// Fingerprint returns an identifier of the given error of the report that is independent of
+// element identifiers and line numbers, so that the same problem yields the same fingerprint
+// after the index is regenerated.
+func (r Report) Fingerprint(err *Error) string {
+	return r.fingerprints[err]
+}
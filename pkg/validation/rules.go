@@ -0,0 +1,598 @@
+package validation
+
+import "strconv"
+
+// Rule describes a single property validated over an LSIF index. Every validation error
+// carries the identifier of the rule that raised it.
+type Rule struct {
+	// ID is the stable identifier of the rule (e.g. LSIF0004).
+	ID string
+	// Name is a short human-readable name of the rule.
+	Name string
+	// Description explains what the rule checks.
+	Description string
+	// Rationale explains why the rule is checked.
+	Rationale string
+	// FailingExample is a minimal LSIF fragment that violates the rule.
+	FailingExample string
+	// PassingExample is a minimal LSIF fragment that satisfies the rule.
+	PassingExample string
+	// DefaultSeverity is the severity of errors raised by the rule unless another severity is
+	// configured. If empty, errors raised by the rule have error severity.
+	DefaultSeverity Severity
+}
+
+const (
+	RuleMetaDataFirst            = "LSIF0001"
+	RuleMetaDataVertex           = "LSIF0002"
+	RuleDocumentVertex           = "LSIF0003"
+	RuleRangeVertex              = "LSIF0004"
+	RuleContainsEdge             = "LSIF0005"
+	RuleItemEdge                 = "LSIF0006"
+	RuleNextEdge                 = "LSIF0007"
+	RuleDefinitionEdge           = "LSIF0008"
+	RuleReferencesEdge           = "LSIF0009"
+	RuleHoverEdge                = "LSIF0010"
+	RuleMonikerEdge              = "LSIF0011"
+	RuleNextMonikerEdge          = "LSIF0012"
+	RulePackageInformationEdge   = "LSIF0013"
+	RuleReachability             = "LSIF0014"
+	RuleRangeOwnership           = "LSIF0015"
+	RuleUniqueRangeOwnership     = "LSIF0016"
+	RuleDisjointRanges           = "LSIF0017"
+	RuleItemContains             = "LSIF0018"
+	RuleMalformedLine            = "LSIF0019"
+	RuleSchema                   = "LSIF0020"
+	RuleMonikerVertex            = "LSIF0021"
+	RulePackageInformationVertex = "LSIF0022"
+	RuleNextMonikerAcyclic       = "LSIF0023"
+	RuleExportMonikerPackage     = "LSIF0024"
+	RuleHoverResultVertex        = "LSIF0025"
+	RuleEmptyHover               = "LSIF0026"
+	RuleDeclarationEdge          = "LSIF0027"
+	RuleTypeDefinitionEdge       = "LSIF0028"
+	RuleImplementationEdge       = "LSIF0029"
+	RuleDiagnosticEdge           = "LSIF0030"
+	RuleDocumentSymbolEdge       = "LSIF0031"
+	RuleFoldingRangeEdge         = "LSIF0032"
+	RuleDocumentLinkEdge         = "LSIF0033"
+	RuleDiagnosticResultVertex   = "LSIF0034"
+	RuleUnknownLabel             = "LSIF0035"
+	RuleEventVertex              = "LSIF0036"
+	RuleEventBracketing          = "LSIF0037"
+	RuleEventScope               = "LSIF0038"
+	RuleProjectVertex            = "LSIF0039"
+	RuleDocumentOwnership        = "LSIF0040"
+	RuleUniqueDocumentOwnership  = "LSIF0041"
+	RuleSingleNextEdge           = "LSIF0042"
+	RuleNextAcyclic              = "LSIF0043"
+	RuleSingleResultEdges        = "LSIF0044"
+	RuleDefinitionResolution     = "LSIF0045"
+	RuleReferenceDefinitions     = "LSIF0046"
+)
+
+const exampleMetaData = `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}`
+
+// exampleIndex is a valid index with a document that contains a single range. The passing
+// examples of most rules extend it, attaching their vertices to the range (whose identifier is 3)
+// so that they are reachable.
+const exampleIndex = exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go","languageId":"go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}`
+
+// exampleDefinition extends exampleIndex with a result set of the range whose definitionResult
+// lists the range.
+const exampleDefinition = exampleIndex + `
+{"id":5,"type":"vertex","label":"resultSet"}
+{"id":6,"type":"edge","label":"next","outV":3,"inV":5}
+{"id":7,"type":"vertex","label":"definitionResult"}
+{"id":8,"type":"edge","label":"textDocument/definition","outV":5,"inV":7}
+{"id":9,"type":"edge","label":"item","outV":7,"inVs":[3],"document":2}`
+
+// exampleProperties is a map from vertex labels to the properties that a valid vertex with that
+// label needs in addition to its identifier, type, and label.
+var exampleProperties = map[string]string{
+	"document":             `,"uri":"file:///project/main.go","languageId":"go"`,
+	"range":                `,"start":{"line":1,"character":1},"end":{"line":1,"character":5}`,
+	"hoverResult":          `,"result":{"contents":"func main()"}`,
+	"diagnosticResult":     `,"result":[]`,
+	"documentSymbolResult": `,"result":[]`,
+	"foldingRangeResult":   `,"result":[]`,
+	"documentLinkResult":   `,"result":[]`,
+	"moniker":              `,"kind":"local","scheme":"go","identifier":"main.Foo"`,
+	"packageInformation":   `,"name":"github.com/example/project","manager":"gomod","version":"v1.0.0"`,
+}
+
+// rules is a map from rule identifiers to the rule they identify.
+var rules = map[string]Rule{
+	RuleMetaDataFirst: {
+		ID:          RuleMetaDataFirst,
+		Name:        "metadata-first",
+		Description: "The metaData vertex is the first element of the index.",
+		Rationale:   "Consumers read the project root from the metaData vertex before resolving any document URI.",
+		FailingExample: `{"id":1,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+` + exampleMetaData,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}`,
+	},
+	RuleMetaDataVertex: {
+		ID:             RuleMetaDataVertex,
+		Name:           "metadata-vertex",
+		Description:    "A single metaData vertex exists and its project root is an absolute URL.",
+		Rationale:      "Document URIs are interpreted relative to the project root, so it must be unique and well-formed.",
+		FailingExample: `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"project"}`,
+		PassingExample: exampleMetaData,
+	},
+	RuleDocumentVertex: {
+		ID:          RuleDocumentVertex,
+		Name:        "document-vertex",
+		Description: "Each document URI is an absolute URL under the project root.",
+		Rationale:   "Documents outside of the project root cannot be mapped onto files of the indexed repository.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///elsewhere/main.go"}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}`,
+	},
+	RuleRangeVertex: {
+		ID:          RuleRangeVertex,
+		Name:        "range-vertex",
+		Description: "Each range has non-negative line and character values and does not end before it starts.",
+		Rationale:   "Inverted or negative ranges cannot be located in the source text.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"range","start":{"line":1,"character":5},"end":{"line":1,"character":1}}`,
+		PassingExample: exampleIndex,
+	},
+	RuleContainsEdge: {
+		ID:          RuleContainsEdge,
+		Name:        "contains-edge",
+		Description: "A contains edge is attached to a project or a document, and refers only to documents if attached to a project and only to ranges if attached to a document.",
+		Rationale:   "Consumers treat every vertex contained by a document as a range of that document, and every vertex contained by a project as one of its documents.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"resultSet"}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}`,
+	},
+	RuleItemEdge: {
+		ID:          RuleItemEdge,
+		Name:        "item-edge",
+		Description: "An item edge is attached to a definition, declaration, type definition, reference, or implementation result and refers to ranges, or also to results of its own type if attached to a reference or implementation result. Item edges attached to a definition or reference result name the document of their ranges.",
+		Rationale:   "Definition and reference results are resolved to locations by following their item edges.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"definitionResult"}
+{"id":4,"type":"vertex","label":"resultSet"}
+{"id":5,"type":"edge","label":"item","outV":3,"inVs":[4],"document":2}`,
+		PassingExample: exampleDefinition,
+	},
+	RuleNextEdge:               makeEdgeRule(RuleNextEdge, "next", "range", "resultSet"),
+	RuleDefinitionEdge:         makeEdgeRule(RuleDefinitionEdge, "textDocument/definition", "resultSet", "definitionResult"),
+	RuleDeclarationEdge:        makeEdgeRule(RuleDeclarationEdge, "textDocument/declaration", "resultSet", "declarationResult"),
+	RuleTypeDefinitionEdge:     makeEdgeRule(RuleTypeDefinitionEdge, "textDocument/typeDefinition", "resultSet", "typeDefinitionResult"),
+	RuleReferencesEdge:         makeEdgeRule(RuleReferencesEdge, "textDocument/references", "resultSet", "referenceResult"),
+	RuleImplementationEdge:     makeEdgeRule(RuleImplementationEdge, "textDocument/implementation", "resultSet", "implementationResult"),
+	RuleHoverEdge:              makeEdgeRule(RuleHoverEdge, "textDocument/hover", "resultSet", "hoverResult"),
+	RuleMonikerEdge:            makeEdgeRule(RuleMonikerEdge, "moniker", "resultSet", "moniker"),
+	RuleNextMonikerEdge:        makeEdgeRule(RuleNextMonikerEdge, "nextMoniker", "moniker", "moniker"),
+	RulePackageInformationEdge: makeEdgeRule(RulePackageInformationEdge, "packageInformation", "moniker", "packageInformation"),
+	RuleDiagnosticEdge:         makeEdgeRule(RuleDiagnosticEdge, "textDocument/diagnostic", "document", "diagnosticResult"),
+	RuleDocumentSymbolEdge:     makeEdgeRule(RuleDocumentSymbolEdge, "textDocument/documentSymbol", "document", "documentSymbolResult"),
+	RuleFoldingRangeEdge:       makeEdgeRule(RuleFoldingRangeEdge, "textDocument/foldingRange", "document", "foldingRangeResult"),
+	RuleDocumentLinkEdge:       makeEdgeRule(RuleDocumentLinkEdge, "textDocument/documentLink", "document", "documentLinkResult"),
+	RuleReachability: {
+		ID:          RuleReachability,
+		Name:        "reachability",
+		Description: "Each vertex (except for metaData, project, document, and $event vertices) is reachable from a range, document, or project.",
+		Rationale:   "Unreachable vertices are never read by consumers and indicate a missing or misdirected edge.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"hoverResult","result":{"contents":"unused"}}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"vertex","label":"hoverResult","result":{"contents":"used"}}
+{"id":5,"type":"edge","label":"textDocument/hover","outV":3,"inV":4}
+{"id":6,"type":"edge","label":"contains","outV":2,"inVs":[3]}`,
+	},
+	RuleRangeOwnership: {
+		ID:          RuleRangeOwnership,
+		Name:        "range-ownership",
+		Description: "Each range is contained by some document.",
+		Rationale:   "A range that belongs to no document cannot be located in the repository.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}`,
+	},
+	RuleUniqueRangeOwnership: {
+		ID:          RuleUniqueRangeOwnership,
+		Name:        "unique-range-ownership",
+		Description: "No range is contained by more than one document.",
+		Rationale:   "A range claimed by several documents has an ambiguous location.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/a.go"}
+{"id":3,"type":"vertex","label":"document","uri":"file:///project/b.go"}
+{"id":4,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[4]}
+{"id":6,"type":"edge","label":"contains","outV":3,"inVs":[4]}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/a.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}`,
+	},
+	RuleDisjointRanges: {
+		ID:          RuleDisjointRanges,
+		Name:        "disjoint-ranges",
+		Description: "The ranges of a single document are either properly nested or completely disjoint.",
+		Rationale:   "Consumers locate the range under a cursor by assuming that ranges do not partially overlap.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"vertex","label":"range","start":{"line":1,"character":3},"end":{"line":1,"character":8}}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[3,4]}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"vertex","label":"range","start":{"line":1,"character":6},"end":{"line":1,"character":8}}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[3,4]}`,
+	},
+	RuleItemContains: {
+		ID:          RuleItemContains,
+		Name:        "item-contains",
+		Description: "The ranges referred to by an item edge are contained by the document named in the edge's document property.",
+		Rationale:   "Consumers resolve the location of each item through the document property of the edge.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/a.go"}
+{"id":3,"type":"vertex","label":"document","uri":"file:///project/b.go"}
+{"id":4,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[4]}
+{"id":6,"type":"vertex","label":"definitionResult"}
+{"id":7,"type":"edge","label":"item","outV":6,"inVs":[4],"document":3}`,
+		PassingExample: exampleDefinition,
+	},
+	RuleMalformedLine: {
+		ID:          RuleMalformedLine,
+		Name:        "malformed-line",
+		Description: "Each line of the index is a JSON object with a valid identifier and a payload of the shape expected for its label.",
+		Rationale:   "Lines that cannot be parsed are skipped, so every element they define and every edge that refers to them is lost.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1`,
+		PassingExample: exampleIndex,
+	},
+	RuleSchema: {
+		ID:          RuleSchema,
+		Name:        "schema",
+		Description: "Each element has the properties that the schema requires for its label, and each edge declared by the schema without a rule of its own refers to vertices of the declared types.",
+		Rationale:   "The schema describes the shape of elements that consumers (and LSIF extensions) rely on.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"range","start":{"line":1,"character":1}}`,
+		PassingExample: exampleIndex,
+	},
+	RuleMonikerVertex: {
+		ID:          RuleMonikerVertex,
+		Name:        "moniker-vertex",
+		Description: "Each moniker has a non-empty scheme and identifier, and a kind of import, export, or local.",
+		Rationale:   "Monikers link symbols across indexes, which is only possible when both ends agree on the scheme and identifier.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"moniker","kind":"export","scheme":"gomod","identifier":""}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"moniker"` + exampleProperties["moniker"] + `}
+{"id":6,"type":"edge","label":"moniker","outV":3,"inV":5}`,
+	},
+	RulePackageInformationVertex: {
+		ID:          RulePackageInformationVertex,
+		Name:        "package-information-vertex",
+		Description: "Each packageInformation vertex has a non-empty name and manager.",
+		Rationale:   "The package name and manager identify the index that defines the symbols of an import moniker.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"packageInformation","name":"github.com/example/project","version":"v1.0.0"}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"moniker"` + exampleProperties["moniker"] + `}
+{"id":6,"type":"edge","label":"moniker","outV":3,"inV":5}
+{"id":7,"type":"vertex","label":"packageInformation"` + exampleProperties["packageInformation"] + `}
+{"id":8,"type":"edge","label":"packageInformation","outV":5,"inV":7}`,
+	},
+	RuleNextMonikerAcyclic: {
+		ID:          RuleNextMonikerAcyclic,
+		Name:        "next-moniker-acyclic",
+		Description: "No moniker can be reached from itself by following nextMoniker edges.",
+		Rationale:   "Consumers follow nextMoniker edges until they reach the last moniker of the chain.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"moniker","kind":"local","scheme":"go","identifier":"a"}
+{"id":3,"type":"vertex","label":"moniker","kind":"local","scheme":"go","identifier":"b"}
+{"id":4,"type":"edge","label":"nextMoniker","outV":2,"inV":3}
+{"id":5,"type":"edge","label":"nextMoniker","outV":3,"inV":2}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"moniker","kind":"local","scheme":"go","identifier":"a"}
+{"id":6,"type":"edge","label":"moniker","outV":3,"inV":5}
+{"id":7,"type":"vertex","label":"moniker","kind":"local","scheme":"go","identifier":"b"}
+{"id":8,"type":"edge","label":"nextMoniker","outV":5,"inV":7}`,
+	},
+	RuleExportMonikerPackage: {
+		ID:          RuleExportMonikerPackage,
+		Name:        "export-moniker-package",
+		Description: "Each export moniker, or a moniker reachable from it by following nextMoniker edges, is attached to a valid packageInformation vertex.",
+		Rationale:   "Other indexes can refer to an exported symbol only through the package that exports it.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"moniker","kind":"export","scheme":"gomod","identifier":"main.Foo"}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"moniker","kind":"export","scheme":"gomod","identifier":"main.Foo"}
+{"id":6,"type":"edge","label":"moniker","outV":3,"inV":5}
+{"id":7,"type":"vertex","label":"packageInformation"` + exampleProperties["packageInformation"] + `}
+{"id":8,"type":"edge","label":"packageInformation","outV":5,"inV":7}`,
+	},
+	RuleHoverResultVertex: {
+		ID:          RuleHoverResultVertex,
+		Name:        "hover-result-vertex",
+		Description: "The contents of each hoverResult are a MarkedString, an array of MarkedStrings without null entries, or a MarkupContent of kind plaintext or markdown, and the range of the hover (if any) has sane bounds.",
+		Rationale:   "Hovers of any other shape, or with null elements, cannot be rendered by consumers.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"hoverResult","result":{"contents":{"kind":"html","value":"<b>func main()</b>"}}}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"hoverResult","result":{"contents":{"kind":"markdown","value":"**func main()**"}}}
+{"id":6,"type":"edge","label":"textDocument/hover","outV":3,"inV":5}`,
+	},
+	RuleEmptyHover: {
+		ID:          RuleEmptyHover,
+		Name:        "empty-hover",
+		Description: "The contents of each hoverResult contain text other than whitespace, and are not an empty array.",
+		Rationale:   "An empty hover is displayed as an empty tooltip; the hover should be omitted instead.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"hoverResult","result":{"contents":"  "}}
+{"id":3,"type":"vertex","label":"hoverResult","result":{"contents":[]}}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"hoverResult"` + exampleProperties["hoverResult"] + `}
+{"id":6,"type":"edge","label":"textDocument/hover","outV":3,"inV":5}`,
+		DefaultSeverity: SeverityWarning,
+	},
+	RuleDiagnosticResultVertex: {
+		ID:          RuleDiagnosticResultVertex,
+		Name:        "diagnostic-result-vertex",
+		Description: "Each diagnostic of a diagnosticResult has a range with sane bounds and a severity between 1 (error) and 4 (hint), if any.",
+		Rationale:   "Diagnostics are displayed at their range with an icon chosen by their severity.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"diagnosticResult","result":[{"severity":5,"message":"unused variable","range":{"start":{"line":1,"character":1},"end":{"line":1,"character":5}}}]}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"diagnosticResult","result":[{"severity":2,"message":"unused variable","range":{"start":{"line":1,"character":1},"end":{"line":1,"character":5}}}]}
+{"id":6,"type":"edge","label":"textDocument/diagnostic","outV":2,"inV":5}`,
+	},
+	RuleUnknownLabel: {
+		ID:          RuleUnknownLabel,
+		Name:        "unknown-label",
+		Description: "Each vertex and edge has a label declared by the schema.",
+		Rationale:   "Elements with other labels (often misspelled ones) are ignored by consumers.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"resultSet"}
+{"id":3,"type":"vertex","label":"definitionResult"}
+{"id":4,"type":"edge","label":"textDocument/defintion","outV":2,"inV":3}`,
+		PassingExample:  exampleDefinition,
+		DefaultSeverity: SeverityWarning,
+	},
+	RuleEventVertex: {
+		ID:          RuleEventVertex,
+		Name:        "event-vertex",
+		Description: "Each $event vertex has a kind of begin or end, a scope of document or project, and data referring to a previously defined vertex of the type named by its scope.",
+		Rationale:   "Streaming consumers use events to learn when the data of a document or project begins and ends.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":3,"type":"vertex","label":"$event","kind":"begin","scope":"project","data":2}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":3,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":2}
+{"id":4,"type":"vertex","label":"$event","kind":"end","scope":"document","data":2}`,
+	},
+	RuleEventBracketing: {
+		ID:          RuleEventBracketing,
+		Name:        "event-bracketing",
+		Description: "Each document or project scope that is begun by an event is ended exactly once by a later event, and scopes begun within another scope end before it.",
+		Rationale:   "Streaming consumers release the data of a scope when it ends, which is only possible if scopes are balanced and do not interleave.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"project","kind":"go"}
+{"id":3,"type":"vertex","label":"$event","kind":"begin","scope":"project","data":2}
+{"id":4,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":5,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":4}
+{"id":6,"type":"vertex","label":"$event","kind":"end","scope":"project","data":2}
+{"id":7,"type":"vertex","label":"$event","kind":"end","scope":"document","data":4}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"project","kind":"go"}
+{"id":3,"type":"vertex","label":"$event","kind":"begin","scope":"project","data":2}
+{"id":4,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[4]}
+{"id":6,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":4}
+{"id":7,"type":"vertex","label":"$event","kind":"end","scope":"document","data":4}
+{"id":8,"type":"vertex","label":"$event","kind":"end","scope":"project","data":2}`,
+	},
+	RuleEventScope: {
+		ID:          RuleEventScope,
+		Name:        "event-scope",
+		Description: "The contains edges of a document with begin and end events, and the ranges they contain, occur between those events, and no edge other than a contains edge from a project refers to the document after its end event.",
+		Rationale:   "Streaming consumers discard the data of a document once it ends, so later data for the document is lost.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":3,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":2}
+{"id":4,"type":"vertex","label":"$event","kind":"end","scope":"document","data":2}
+{"id":5,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":6,"type":"edge","label":"contains","outV":2,"inVs":[5]}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":3,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":2}
+{"id":4,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[4]}
+{"id":6,"type":"vertex","label":"$event","kind":"end","scope":"document","data":2}`,
+	},
+	RuleProjectVertex: {
+		ID:          RuleProjectVertex,
+		Name:        "project-vertex",
+		Description: "Each project has a non-empty kind, and a non-empty name if it has a name at all.",
+		Rationale:   "Consumers of multi-project indexes distinguish projects by their kind and name.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"project","kind":"go","name":""}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"project","kind":"go","name":"example"}`,
+	},
+	RuleDocumentOwnership: {
+		ID:          RuleDocumentOwnership,
+		Name:        "document-ownership",
+		Description: "If the index has any project, each document is contained by some project.",
+		Rationale:   "A document that belongs to no project of a multi-project index cannot be attributed to a project.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"project","kind":"go"}
+{"id":3,"type":"vertex","label":"document"` + exampleProperties["document"] + `}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"project","kind":"go"}
+{"id":3,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}`,
+	},
+	RuleUniqueDocumentOwnership: {
+		ID:          RuleUniqueDocumentOwnership,
+		Name:        "unique-document-ownership",
+		Description: "No document is contained by more than one project.",
+		Rationale:   "A document claimed by several projects has an ambiguous owner.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"project","kind":"go","name":"a"}
+{"id":3,"type":"vertex","label":"project","kind":"go","name":"b"}
+{"id":4,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[4]}
+{"id":6,"type":"edge","label":"contains","outV":3,"inVs":[4]}`,
+		PassingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"project","kind":"go","name":"a"}
+{"id":3,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}`,
+	},
+	RuleSingleNextEdge: {
+		ID:          RuleSingleNextEdge,
+		Name:        "single-next-edge",
+		Description: "Each range and result set has at most one next edge.",
+		Rationale:   "Consumers follow a single chain of next edges from a range to the result set that holds its results.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":3,"type":"vertex","label":"resultSet"}
+{"id":4,"type":"vertex","label":"resultSet"}
+{"id":5,"type":"edge","label":"next","outV":2,"inV":3}
+{"id":6,"type":"edge","label":"next","outV":2,"inV":4}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"resultSet"}
+{"id":6,"type":"vertex","label":"resultSet"}
+{"id":7,"type":"edge","label":"next","outV":3,"inV":5}
+{"id":8,"type":"edge","label":"next","outV":5,"inV":6}`,
+	},
+	RuleNextAcyclic: {
+		ID:          RuleNextAcyclic,
+		Name:        "next-acyclic",
+		Description: "No range or result set can be reached from itself by following next edges.",
+		Rationale:   "Consumers follow next edges until they reach the last result set of the chain.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"resultSet"}
+{"id":3,"type":"vertex","label":"resultSet"}
+{"id":4,"type":"edge","label":"next","outV":2,"inV":3}
+{"id":5,"type":"edge","label":"next","outV":3,"inV":2}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"resultSet"}
+{"id":6,"type":"vertex","label":"resultSet"}
+{"id":7,"type":"edge","label":"next","outV":3,"inV":5}
+{"id":8,"type":"edge","label":"next","outV":5,"inV":6}`,
+	},
+	RuleSingleResultEdges: {
+		ID:          RuleSingleResultEdges,
+		Name:        "single-result-edges",
+		Description: "Each range and result set has at most one textDocument/definition, textDocument/references, and textDocument/hover edge.",
+		Rationale:   "Consumers stop at the first result of each kind along a chain of next edges, so additional results are silently ignored.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"resultSet"}
+{"id":3,"type":"vertex","label":"hoverResult"` + exampleProperties["hoverResult"] + `}
+{"id":4,"type":"vertex","label":"hoverResult"` + exampleProperties["hoverResult"] + `}
+{"id":5,"type":"edge","label":"textDocument/hover","outV":2,"inV":3}
+{"id":6,"type":"edge","label":"textDocument/hover","outV":2,"inV":4}`,
+		PassingExample: exampleIndex + `
+{"id":5,"type":"vertex","label":"resultSet"}
+{"id":6,"type":"edge","label":"next","outV":3,"inV":5}
+{"id":7,"type":"vertex","label":"hoverResult"` + exampleProperties["hoverResult"] + `}
+{"id":8,"type":"edge","label":"textDocument/hover","outV":5,"inV":7}`,
+	},
+	RuleDefinitionResolution: {
+		ID:          RuleDefinitionResolution,
+		Name:        "definition-resolution",
+		Description: "Each range listed by a definitionResult resolves to that definitionResult through its own chain of next edges.",
+		Rationale:   "Navigating from a definition to its references starts at the definition range and follows its next edges, so a definition that resolves elsewhere (or nowhere) loses its references.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"document"` + exampleProperties["document"] + `}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}
+{"id":5,"type":"vertex","label":"resultSet"}
+{"id":6,"type":"vertex","label":"resultSet"}
+{"id":7,"type":"vertex","label":"definitionResult"}
+{"id":8,"type":"edge","label":"textDocument/definition","outV":5,"inV":7}
+{"id":9,"type":"edge","label":"item","outV":7,"inVs":[3],"document":2}`,
+		PassingExample: exampleDefinition,
+	},
+	RuleReferenceDefinitions: {
+		ID:          RuleReferenceDefinitions,
+		Name:        "reference-definitions",
+		Description: "Each referenceResult lists the ranges of the definitionResult of the same range or result set as items with a definitions property, either directly or through the referenceResults it links to.",
+		Rationale:   "Find References includes the definitions of a symbol only if its referenceResult lists them as definitions.",
+		FailingExample: exampleDefinition + `
+{"id":10,"type":"vertex","label":"referenceResult"}
+{"id":11,"type":"edge","label":"textDocument/references","outV":5,"inV":10}
+{"id":12,"type":"edge","label":"item","outV":10,"inVs":[3],"document":2,"property":"references"}`,
+		PassingExample: exampleDefinition + `
+{"id":10,"type":"vertex","label":"referenceResult"}
+{"id":11,"type":"edge","label":"textDocument/references","outV":5,"inV":10}
+{"id":12,"type":"edge","label":"item","outV":10,"inVs":[3],"document":2,"property":"definitions"}`,
+	},
+}
+
+// makeEdgeRule creates a rule for an edge whose adjacent vertices are declared by the schema. The
+// passing example attaches an edge with the given label to vertices with the given out and in
+// labels, and the failing example attaches the edge to a range instead.
+func makeEdgeRule(id, label, outLabel, inLabel string) Rule {
+	outElements, outV, inV := exampleOutVertex(outLabel)
+
+	return Rule{
+		ID:          id,
+		Name:        label + "-edge",
+		Description: "A " + label + " edge attaches vertices of the expected types to one another.",
+		Rationale:   "Consumers traverse " + label + " edges assuming the type of the vertex at either end.",
+		FailingExample: exampleMetaData + `
+{"id":2,"type":"vertex","label":"` + outLabel + `"` + exampleProperties[outLabel] + `}
+{"id":3,"type":"vertex","label":"range"` + exampleProperties["range"] + `}
+{"id":4,"type":"edge","label":"` + label + `","outV":2,"inV":3}`,
+		PassingExample: exampleIndex + outElements + `
+{"id":` + strconv.Itoa(inV) + `,"type":"vertex","label":"` + inLabel + `"` + exampleProperties[inLabel] + `}
+{"id":` + strconv.Itoa(inV+1) + `,"type":"edge","label":"` + label + `","outV":` + strconv.Itoa(outV) + `,"inV":` + strconv.Itoa(inV) + `}`,
+	}
+}
+
+// exampleOutVertex returns the elements that extend exampleIndex with a vertex with the given
+// label that is reachable from its range, along with the identifier of that vertex and the first
+// unused identifier. The document and the range of exampleIndex are used as they are.
+func exampleOutVertex(label string) (string, int, int) {
+	switch label {
+	case "document":
+		return "", 2, 5
+	case "range":
+		return "", 3, 5
+	case "moniker":
+		return `
+{"id":5,"type":"vertex","label":"moniker"` + exampleProperties["moniker"] + `}
+{"id":6,"type":"edge","label":"moniker","outV":3,"inV":5}`, 5, 7
+	}
+
+	return `
+{"id":5,"type":"vertex","label":"` + label + `"` + exampleProperties[label] + `}
+{"id":6,"type":"edge","label":"next","outV":3,"inV":5}`, 5, 7
+}
+
+// Rules returns every built-in rule ordered by identifier.
+func Rules() []Rule {
+	return defaultRegistry.Rules()
+}
+
+// LookupRule returns the built-in rule with the given identifier or name.
+func LookupRule(idOrName string) (Rule, bool) {
+	return defaultRegistry.LookupRule(idOrName)
+}
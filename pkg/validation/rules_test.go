@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleExamples(t *testing.T) {
+	for _, rule := range Rules() {
+		for _, diskBacked := range []bool{false, true} {
+			if rule.PassingExample != "" {
+				report, err := Validate(strings.NewReader(rule.PassingExample), Options{DiskBacked: diskBacked})
+				if err != nil {
+					t.Fatalf("unexpected error validating passing example of %s: %s", rule.ID, err)
+				}
+				if len(report.Errors) != 0 {
+					t.Errorf("unexpected errors validating passing example of %s (disk backed: %v):\n%s", rule.ID, diskBacked, formatErrors(report))
+				}
+			}
+
+			if rule.FailingExample != "" {
+				report, err := Validate(strings.NewReader(rule.FailingExample), Options{DiskBacked: diskBacked})
+				if err != nil {
+					t.Fatalf("unexpected error validating failing example of %s: %s", rule.ID, err)
+				}
+				if !raisesRule(report, rule.ID) {
+					t.Errorf("expected failing example of %s to raise it (disk backed: %v):\n%s", rule.ID, diskBacked, formatErrors(report))
+				}
+			}
+		}
+	}
+}
+
+// raisesRule returns true if the report has an error raised by the given rule.
+func raisesRule(report Report, ruleID string) bool {
+	for _, err := range report.Errors {
+		if err.Rule == ruleID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,284 @@
+package validation
+
+//go:generate go run ./internal/gendocs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Schema declares the vertex and edge labels of LSIF and the constraints on the elements with
+// each label. The default schema and a description of its format are in schema.yaml.
+type Schema struct {
+	Vertices []VertexSchema `yaml:"vertices"`
+	Edges    []EdgeSchema   `yaml:"edges"`
+}
+
+// VertexSchema declares a vertex label and the properties required of vertices with that label.
+// Violations are attributed to the given rule (an identifier or name), or to RuleSchema if empty.
+type VertexSchema struct {
+	Label      string   `yaml:"label"`
+	Rule       string   `yaml:"rule,omitempty"`
+	Properties []string `yaml:"properties,omitempty"`
+}
+
+// EdgeSchema declares the labels allowed for the adjacent vertices of edges with a given label,
+// and the properties required of such edges. If When is non-nil, the declaration applies only to
+// edges whose outV vertex satisfies the condition. Violations are attributed to the given rule
+// (an identifier or name), or to RuleSchema if empty.
+type EdgeSchema struct {
+	Label      string         `yaml:"label"`
+	Rule       string         `yaml:"rule,omitempty"`
+	When       *EdgeCondition `yaml:"when,omitempty"`
+	OutV       []string       `yaml:"outV,omitempty"`
+	InV        []string       `yaml:"inV,omitempty"`
+	Properties []string       `yaml:"properties,omitempty"`
+}
+
+// EdgeCondition is a condition on the outV vertex of an edge.
+type EdgeCondition struct {
+	// OutV is the set of labels of which the outV vertex must have one.
+	OutV []string `yaml:"outV"`
+}
+
+// holds returns true if the given label of an outV vertex satisfies the condition.
+func (c *EdgeCondition) holds(outLabel string) bool {
+	return c == nil || containsLabel(c.OutV, outLabel)
+}
+
+// ParseSchema parses a schema from its YAML representation.
+func ParseSchema(data []byte) (*Schema, error) {
+	schema := &Schema{}
+	if err := yaml.UnmarshalStrict(data, schema); err != nil {
+		return nil, err
+	}
+
+	for _, vertex := range schema.Vertices {
+		if vertex.Label == "" {
+			return nil, fmt.Errorf("vertex declared without a label")
+		}
+	}
+	for _, edge := range schema.Edges {
+		if edge.Label == "" {
+			return nil, fmt.Errorf("edge declared without a label")
+		}
+		if edge.When != nil && len(edge.When.OutV) == 0 {
+			return nil, fmt.Errorf("edge %s declared with an empty condition", edge.Label)
+		}
+	}
+
+	return schema, nil
+}
+
+// DefaultSchema returns the built-in schema.
+func DefaultSchema() *Schema {
+	schema, err := ParseSchema([]byte(DefaultSchemaYAML))
+	if err != nil {
+		panic(fmt.Sprintf("malformed default schema: %s", err))
+	}
+
+	return schema
+}
+
+// compileSchema creates the element validators that check the given schema, keyed by label. The
+// rules named by the schema are resolved with the given function.
+func compileSchema(schema *Schema, lookupRule func(idOrName string) (Rule, bool)) (vertexValidators, edgeValidators map[string][]elementRule, _ error) {
+	resolve := func(idOrName string) (string, error) {
+		if idOrName == "" {
+			return RuleSchema, nil
+		}
+
+		rule, ok := lookupRule(idOrName)
+		if !ok {
+			return "", fmt.Errorf("unknown rule %s", idOrName)
+		}
+
+		return rule.ID, nil
+	}
+
+	vertexValidators = map[string][]elementRule{}
+	for _, vertex := range schema.Vertices {
+		if _, ok := vertexValidators[vertex.Label]; ok {
+			return nil, nil, fmt.Errorf("vertex %s declared multiple times", vertex.Label)
+		}
+
+		ruleID, err := resolve(vertex.Rule)
+		if err != nil {
+			return nil, nil, fmt.Errorf("vertex %s: %v", vertex.Label, err)
+		}
+
+		vertexValidators[vertex.Label] = nil
+		if len(vertex.Properties) > 0 {
+			vertexValidators[vertex.Label] = []elementRule{{ruleID, makeVertexSchemaValidator(vertex)}}
+		}
+	}
+
+	edgeRuleIDs := map[string]string{}
+	edgesByLabel := map[string][]EdgeSchema{}
+	var labels []string
+	for _, edge := range schema.Edges {
+		ruleID, err := resolve(edge.Rule)
+		if err != nil {
+			return nil, nil, fmt.Errorf("edge %s: %v", edge.Label, err)
+		}
+
+		if otherRuleID, ok := edgeRuleIDs[edge.Label]; !ok {
+			edgeRuleIDs[edge.Label] = ruleID
+			labels = append(labels, edge.Label)
+		} else if otherRuleID != ruleID {
+			return nil, nil, fmt.Errorf("edge %s declared with rules %s and %s", edge.Label, otherRuleID, ruleID)
+		}
+
+		edgesByLabel[edge.Label] = append(edgesByLabel[edge.Label], edge)
+	}
+
+	edgeValidators = map[string][]elementRule{}
+	for _, label := range labels {
+		edgeValidators[label] = []elementRule{{edgeRuleIDs[label], makeEdgeSchemaValidator(edgesByLabel[label])}}
+	}
+
+	return vertexValidators, edgeValidators, nil
+}
+
+// makeVertexSchemaValidator returns an ElementValidator that ensures the vertex has the properties
+// required by the given declaration.
+func makeVertexSchemaValidator(vertex VertexSchema) ElementValidator {
+	return func(ctx *ValidationContext, lineContext LineContext) bool {
+		return validateProperties(ctx, lineContext, vertex.Properties)
+	}
+}
+
+// makeEdgeSchemaValidator returns an ElementValidator that ensures the edge is well-formed (see
+// validateEdge) and that it satisfies the first of the given declarations whose condition holds.
+func makeEdgeSchemaValidator(edges []EdgeSchema) ElementValidator {
+	return func(ctx *ValidationContext, lineContext LineContext) bool {
+		var edge *EdgeSchema
+
+		outValidator := func(ctx *ValidationContext, edgeContext LineContext, outV int, outLabel string) bool {
+			for i := range edges {
+				if edges[i].When.holds(outLabel) {
+					edge = &edges[i]
+					break
+				}
+			}
+
+			return edge == nil || len(edge.OutV) == 0 || validateLabels(ctx, edgeContext, outV, outLabel, edge.OutV)
+		}
+
+		inValidator := func(ctx *ValidationContext, edgeContext LineContext, outLabel string, inV int, inLabel string) bool {
+			return edge == nil || len(edge.InV) == 0 || validateLabels(ctx, edgeContext, inV, inLabel, edge.InV)
+		}
+
+		if !validateEdge(ctx, lineContext, outValidator, inValidator) {
+			return false
+		}
+
+		return edge == nil || validateProperties(ctx, lineContext, edge.Properties)
+	}
+}
+
+// makeUnknownLabelValidator returns an ElementValidator that reports the label of the element as
+// unknown, suggesting the most similar of the labels of the given map if there is one.
+func makeUnknownLabelValidator(validators map[string][]elementRule) ElementValidator {
+	labels := make([]string, 0, len(validators))
+	for label := range validators {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	return func(ctx *ValidationContext, lineContext LineContext) bool {
+		label := lineContext.Element.Label
+		if suggestion, ok := closestLabel(labels, label); ok {
+			ctx.AddError("unknown %s label %q (did you mean %q?)", lineContext.Element.Type, label, suggestion).AddContext(lineContext)
+		} else {
+			ctx.AddError("unknown %s label %q", lineContext.Element.Type, label).AddContext(lineContext)
+		}
+
+		return false
+	}
+}
+
+// maxSuggestionDistance is the maximum edit distance between an unknown label and a label that
+// is suggested in its place.
+const maxSuggestionDistance = 2
+
+// closestLabel returns the first of the given labels with the smallest edit distance to the
+// given label, if that distance is at most maxSuggestionDistance.
+func closestLabel(labels []string, label string) (string, bool) {
+	closest, closestDistance := "", maxSuggestionDistance+1
+	for _, candidate := range labels {
+		if distance := editDistance(candidate, label); distance < closestDistance {
+			closest, closestDistance = candidate, distance
+		}
+	}
+
+	return closest, closest != ""
+}
+
+// editDistance returns the Levenshtein distance between the given strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// min returns the smallest of the given integers.
+func min(first int, rest ...int) int {
+	for _, value := range rest {
+		if value < first {
+			first = value
+		}
+	}
+
+	return first
+}
+
+// validateProperties marks an error and returns false if the given element does not have each of
+// the given properties.
+func validateProperties(ctx *ValidationContext, lineContext LineContext, properties []string) bool {
+	var missing []string
+	for _, property := range properties {
+		if !containsLabel(lineContext.Properties, property) {
+			missing = append(missing, property)
+		}
+	}
+
+	if len(missing) > 0 {
+		ctx.AddError("missing required properties: %s", strings.Join(missing, ", ")).AddContext(lineContext)
+		return false
+	}
+
+	return true
+}
+
+// containsLabel returns true if the given label is in the given set of labels.
+func containsLabel(labels []string, label string) bool {
+	for _, candidate := range labels {
+		if candidate == label {
+			return true
+		}
+	}
+
+	return false
+}
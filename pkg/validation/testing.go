@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// AssertValid validates the LSIF index read from the given reader and fails the test if the index
+// cannot be read or has any error with error severity. The failure message lists each reported
+// error (see Options.MaxErrors) with its rule and the relevant lines of the index. If the index
+// only has warnings, they are logged without failing the test. The report is returned so that
+// the caller can make further assertions.
+func AssertValid(tb testing.TB, r io.Reader, opts Options) Report {
+	tb.Helper()
+
+	report, err := Validate(r, opts)
+	if err != nil {
+		tb.Fatalf("failed to validate LSIF index: %s", err)
+		return report
+	}
+
+	if len(report.Errors) == 0 {
+		return report
+	}
+
+	summary := formatErrors(report)
+	if numFailures := report.NumFailures(); numFailures > 0 {
+		tb.Errorf("LSIF index has %d errors and %d warnings:\n%s", numFailures, len(report.Errors)-numFailures, summary)
+	} else {
+		tb.Logf("LSIF index has %d warnings:\n%s", len(report.Errors), summary)
+	}
+
+	return report
+}
+
+// formatErrors formats the reported errors of the given report as a numbered list, followed by
+// the number of errors omitted from the list.
+func formatErrors(report Report) string {
+	reported := report.ReportedErrors()
+
+	lines := make([]string, 0, len(reported)+1)
+	for i, err := range reported {
+		lines = append(lines, fmt.Sprintf("%d) %s", i+1, err))
+	}
+	if omitted := len(report.Errors) - len(reported); omitted > 0 {
+		lines = append(lines, fmt.Sprintf("... and %d more errors", omitted))
+	}
+
+	return strings.Join(lines, "\n")
+}
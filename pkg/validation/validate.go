@@ -0,0 +1,119 @@
+// Package validation validates LSIF indexes. Validate checks an index against the rules of the
+// validator and returns a Report of the errors found; AssertValid does the same from within a
+// test and fails the test if the index is invalid.
+package validation
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	reader "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// Options configures a validation. The zero value checks every rule with its default severity.
+type Options struct {
+	// Severities is a map from rule identifiers to the severity of errors raised by that rule.
+	// Rules missing from this map have their default severity (see Rule.DefaultSeverity).
+	Severities map[string]Severity
+
+	// MaxErrors is the maximum number of errors returned by Report.ReportedErrors (0 for no
+	// limit). All errors are retained in Report.Errors.
+	MaxErrors int
+
+	// MaxDetailedErrors is the maximum number of individual errors recorded by a single
+	// relationship validator before the remaining violations are summarized. Zero selects
+	// DefaultMaxDetailedErrors and a negative value removes the limit.
+	MaxDetailedErrors int
+
+	// SourceRoot is a local directory containing the indexed sources. If non-empty, each
+	// document must refer to a file within this directory.
+	SourceRoot string
+
+	// ReachabilityIgnoreList is the set of vertex labels exempt from the reachability check.
+	// If nil, DefaultReachabilityIgnoreList is used.
+	ReachabilityIgnoreList []string
+
+	// DiskBacked stores vertex payloads in a temporary file while validating instead of in
+	// memory, which reduces the memory required to validate large indexes.
+	DiskBacked bool
+
+	// Registry holds the rules and validators to check. If nil, the built-in rules and
+	// validators are checked.
+	Registry *Registry
+
+	// Progress, if non-nil, is updated while the index is being validated.
+	Progress *Progress
+}
+
+// Progress reports the state of a running validation. Its methods are safe to call from other
+// goroutines while Validate is running.
+type Progress struct {
+	ctx  *ValidationContext
+	lock sync.RWMutex
+}
+
+// Counts returns the number of vertices and edges read so far and the number of errors raised
+// so far.
+func (p *Progress) Counts() (vertices, edges uint64, errors int) {
+	p.lock.RLock()
+	ctx := p.ctx
+	p.lock.RUnlock()
+
+	if ctx == nil {
+		return 0, 0, 0
+	}
+
+	return atomic.LoadUint64(&ctx.NumVertices), atomic.LoadUint64(&ctx.NumEdges), ctx.numErrors()
+}
+
+// setContext attaches the context of a running validation.
+func (p *Progress) setContext(ctx *ValidationContext) {
+	p.lock.Lock()
+	p.ctx = ctx
+	p.lock.Unlock()
+}
+
+// Validate reads the LSIF index from the given reader and checks it against every rule of the
+// registry of the given options. An error is returned only if the index cannot be read or the
+// validation cannot be performed; problems with the index itself are described by the returned
+// report.
+func Validate(r io.Reader, opts Options) (_ Report, err error) {
+	ctx := NewValidationContext()
+	if opts.DiskBacked {
+		stasher, err := reader.NewDiskStasher("")
+		if err != nil {
+			return Report{}, err
+		}
+
+		ctx.Stasher = stasher
+	}
+	defer func() {
+		if closeErr := ctx.Stasher.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+
+	validator := &Validator{Context: ctx, Registry: opts.Registry}
+
+	ctx.Severities = validator.registry().severities(opts.Severities)
+	ctx.MaxErrors = opts.MaxErrors
+	ctx.SourceRoot = opts.SourceRoot
+	if opts.MaxDetailedErrors > 0 {
+		ctx.MaxDetailedErrors = opts.MaxDetailedErrors
+	} else if opts.MaxDetailedErrors < 0 {
+		ctx.MaxDetailedErrors = 0
+	}
+	if opts.ReachabilityIgnoreList != nil {
+		ctx.ReachabilityIgnoreList = opts.ReachabilityIgnoreList
+	}
+	if opts.Progress != nil {
+		opts.Progress.setContext(ctx)
+	}
+
+	if err := validator.Validate(r); err != nil {
+		return Report{}, err
+	}
+
+	return newReport(ctx), nil
+}
@@ -0,0 +1,423 @@
+package validation
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/lsif-test/internal/testindex"
+)
+
+const validIndex = `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":0},"end":{"line":1,"character":4}}
+{"id":4,"type":"edge","label":"contains","outV":2,"inVs":[3]}
+`
+
+// invalidIndex has an illegal range and a range that is not owned by any document.
+const invalidIndex = validIndex + `{"id":5,"type":"vertex","label":"range","start":{"line":2,"character":5},"end":{"line":1,"character":0}}
+{"id":6,"type":"vertex","label":"range","start":{"line":3,"character":0},"end":{"line":3,"character":4}}
+`
+
+func TestValidate(t *testing.T) {
+	report, err := Validate(strings.NewReader(validIndex), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error validating index: %s", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Errorf("unexpected errors: %v", report.Errors)
+	}
+	if report.NumVertices != 3 || report.NumEdges != 1 {
+		t.Errorf("unexpected counts: want 3 vertices and 1 edge, have %d and %d", report.NumVertices, report.NumEdges)
+	}
+
+	report, err = Validate(strings.NewReader(invalidIndex), Options{
+		Severities: map[string]Severity{RuleRangeOwnership: SeverityWarning},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error validating index: %s", err)
+	}
+
+	var rules []string
+	for _, err := range report.Errors {
+		rules = append(rules, fmt.Sprintf("%s:%s", err.Rule, err.Severity))
+	}
+	if expected := []string{RuleRangeVertex + ":error", RuleReachability + ":error", RuleRangeOwnership + ":warning"}; fmt.Sprint(rules) != fmt.Sprint(expected) {
+		t.Errorf("unexpected errors: want %v, have %v", expected, rules)
+	}
+	if report.NumFailures() != 2 {
+		t.Errorf("unexpected number of failures: want 2, have %d", report.NumFailures())
+	}
+}
+
+func TestLeadingBlankLines(t *testing.T) {
+	report, err := Validate(strings.NewReader("\n\n"+validIndex), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error validating index: %s", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Errorf("unexpected errors:\n%s", formatErrors(report))
+	}
+}
+
+func TestDisabledUniqueRangeOwnership(t *testing.T) {
+	index := `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/a.go"}
+{"id":3,"type":"vertex","label":"document","uri":"file:///project/b.go"}
+{"id":4,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":5,"type":"vertex","label":"range","start":{"line":1,"character":3},"end":{"line":1,"character":8}}
+{"id":6,"type":"edge","label":"contains","outV":2,"inVs":[4,5]}
+{"id":7,"type":"edge","label":"contains","outV":3,"inVs":[4]}
+`
+
+	for _, severity := range []Severity{SeverityError, SeverityWarning, SeverityOff} {
+		report, err := Validate(strings.NewReader(index), Options{
+			Severities: map[string]Severity{RuleUniqueRangeOwnership: severity},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error validating index: %s", err)
+		}
+
+		// Ranges claimed by multiple documents are excluded from relationship validation only
+		// if their claims are errors
+		if raisesRule(report, RuleDisjointRanges) != (severity != SeverityError) {
+			t.Errorf("unexpected disjoint ranges errors with %s severity:\n%s", severity, formatErrors(report))
+		}
+	}
+}
+
+func TestDiskBackedReportsAreIdentical(t *testing.T) {
+	index := testindex.Invalid()
+
+	var reports [][]string
+	for _, diskBacked := range []bool{false, true} {
+		report, err := Validate(bytes.NewReader(index), Options{DiskBacked: diskBacked})
+		if err != nil {
+			t.Fatalf("unexpected error validating index: %s", err)
+		}
+		if len(report.Errors) == 0 {
+			t.Fatalf("expected validation errors")
+		}
+
+		errs := make([]string, 0, len(report.Errors))
+		for _, err := range report.Errors {
+			errs = append(errs, fmt.Sprintf("%s %s", report.Fingerprint(err), err))
+		}
+		reports = append(reports, errs)
+	}
+
+	if !reflect.DeepEqual(reports[0], reports[1]) {
+		t.Fatalf("disk-backed report differs from in-memory report:\n%s\n\nvs\n\n%s", strings.Join(reports[0], "\n"), strings.Join(reports[1], "\n"))
+	}
+}
+
+func TestAssertValid(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	AssertValid(tb, strings.NewReader(validIndex), Options{})
+	if tb.failed {
+		t.Errorf("unexpected failure: %s", tb.message)
+	}
+
+	tb = &recordingTB{TB: t}
+	AssertValid(tb, strings.NewReader(invalidIndex), Options{})
+	if !tb.failed {
+		t.Fatalf("expected failure")
+	}
+	if !strings.Contains(tb.message, "LSIF index has 3 errors") || !strings.Contains(tb.message, "on line #5") {
+		t.Errorf("unexpected failure message: %s", tb.message)
+	}
+}
+
+// recordingTB records the failures of a test instead of failing it.
+type recordingTB struct {
+	testing.TB
+	failed  bool
+	message string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.failed = true
+	tb.message = fmt.Sprintf(format, args...)
+}
+
+func (tb *recordingTB) Logf(format string, args ...interface{}) {}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.RegisterRule(Rule{ID: "CUSTOM0001", Name: "short-documents"}); err != nil {
+		t.Fatalf("unexpected error registering rule: %s", err)
+	}
+	if err := registry.RegisterRule(Rule{ID: "CUSTOM0002", Name: "range-vertex"}); err == nil {
+		t.Errorf("expected error registering rule with conflicting name")
+	}
+	if err := registry.RegisterVertexValidator("range", "CUSTOM0002", nil); err == nil {
+		t.Errorf("expected error registering validator of unknown rule")
+	}
+
+	_ = registry.RegisterVertexValidator("range", "CUSTOM0001", func(ctx *ValidationContext, lineContext LineContext) bool {
+		ctx.AddError("range on line %d", lineContext.Index).AddContext(lineContext)
+		return false
+	})
+	_ = registry.RegisterRelationshipValidator("CUSTOM0001", func(ctx *ValidationContext) bool {
+		if n := len(ctx.Graph().VerticesWithLabel("range")); n > 1 {
+			ctx.AddError("document has %d ranges", n)
+			return false
+		}
+
+		return true
+	})
+
+	report, err := Validate(strings.NewReader(invalidIndex), Options{
+		Registry:   registry,
+		Severities: map[string]Severity{RuleReachability: SeverityOff, RuleRangeOwnership: SeverityOff, "CUSTOM0001": SeverityWarning},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error validating index: %s", err)
+	}
+
+	var messages []string
+	for _, err := range report.Errors {
+		messages = append(messages, fmt.Sprintf("%s:%s:%s", err.Rule, err.Severity, err.Message))
+	}
+	expected := []string{
+		"CUSTOM0001:warning:range on line 3",
+		"CUSTOM0001:warning:range on line 5",
+		"CUSTOM0001:warning:range on line 6",
+		"CUSTOM0001:warning:document has 3 ranges",
+		RuleRangeVertex + ":error:illegal range extents",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected errors: want\n%s\nhave\n%s", strings.Join(expected, "\n"), strings.Join(messages, "\n"))
+	}
+}
+
+func TestValidateMessages(t *testing.T) {
+	withoutReachability := map[string]Severity{RuleReachability: SeverityOff}
+
+	// linkedReferenceResults links a referenceResult to another by an item edge, which must not
+	// be mistaken for an item edge referring to ranges even if reference definitions are not checked
+	linkedReferenceResults := `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"vertex","label":"range","start":{"line":2,"character":1},"end":{"line":2,"character":5}}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[3,4]}
+{"id":6,"type":"vertex","label":"resultSet"}
+{"id":7,"type":"edge","label":"next","outV":3,"inV":6}
+{"id":8,"type":"edge","label":"next","outV":4,"inV":6}
+{"id":9,"type":"vertex","label":"definitionResult"}
+{"id":10,"type":"edge","label":"textDocument/definition","outV":6,"inV":9}
+{"id":11,"type":"edge","label":"item","outV":9,"inVs":[3],"document":2}
+{"id":12,"type":"vertex","label":"referenceResult"}
+{"id":13,"type":"edge","label":"textDocument/references","outV":6,"inV":12}
+{"id":14,"type":"vertex","label":"referenceResult"}
+{"id":15,"type":"edge","label":"item","outV":12,"inVs":[14],"document":2,"property":"referenceResults"}
+{"id":16,"type":"edge","label":"item","outV":14,"inVs":[3],"document":2,"property":"definitions"}
+{"id":17,"type":"edge","label":"item","outV":14,"inVs":[4],"document":2,"property":"references"}
+`
+
+	testCases := []struct {
+		name       string
+		index      string
+		severities map[string]Severity
+		expected   []string
+	}{
+		{
+			name: "monikers",
+			index: `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"moniker","kind":"local","scheme":"go","identifier":"a"}
+{"id":3,"type":"vertex","label":"moniker","kind":"export","scheme":"gomod","identifier":"b"}
+{"id":4,"type":"vertex","label":"moniker","kind":"export","scheme":"gomod","identifier":"c"}
+{"id":5,"type":"vertex","label":"moniker","kind":"export","scheme":"gomod","identifier":"d"}
+{"id":6,"type":"vertex","label":"packageInformation","name":"github.com/example/project","manager":"gomod"}
+{"id":7,"type":"edge","label":"nextMoniker","outV":2,"inV":3}
+{"id":8,"type":"edge","label":"nextMoniker","outV":3,"inV":4}
+{"id":9,"type":"edge","label":"nextMoniker","outV":4,"inV":3}
+{"id":10,"type":"edge","label":"packageInformation","outV":4,"inV":6}
+`,
+			severities: withoutReachability,
+			expected: []string{
+				RuleNextMonikerAcyclic + ":error: nextMoniker edges form a cycle through monikers 3, 4 (2 lines)",
+				RuleExportMonikerPackage + ":error: export moniker 5 has no package information (1 lines)",
+			},
+		},
+		{
+			name: "hover results",
+			index: `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"hoverResult","result":{"contents":["func main()",null]}}
+{"id":3,"type":"vertex","label":"hoverResult","result":{"contents":{"kind":"markdown","value":" "}}}
+{"id":4,"type":"vertex","label":"hoverResult","result":{"contents":{"kind":"markdown","value":"func main()"},"range":{"start":{"line":1,"character":5},"end":{"line":1,"character":1}}}}
+{"id":5,"type":"vertex","label":"hoverResult","result":{"contents":[]}}
+{"id":6,"type":"vertex","label":"hoverResult","result":{"contents":[{"language":"go","value":"func main()"},{"kind":"markdown","value":"docs"}]}}
+`,
+			severities: withoutReachability,
+			expected: []string{
+				RuleHoverResultVertex + ":error: hover contents contains null (1 lines)",
+				RuleHoverResultVertex + ":error: illegal range extents of hover (1 lines)",
+				RuleEmptyHover + ":warning: hover is empty (1 lines)",
+				RuleEmptyHover + ":warning: hover is empty (1 lines)",
+				RuleHoverResultVertex + ":error: hover contents array contains a MarkupContent (1 lines)",
+			},
+		},
+		{
+			name: "unknown labels",
+			index: validIndex + `{"id":5,"type":"vertex","label":"resultSet"}
+{"id":6,"type":"edge","label":"nxet","outV":3,"inV":5}
+{"id":7,"type":"vertex","label":"customResult"}
+`,
+			severities: withoutReachability,
+			expected: []string{
+				RuleUnknownLabel + `:warning: unknown edge label "nxet" (did you mean "next"?) (1 lines)`,
+				RuleUnknownLabel + `:warning: unknown vertex label "customResult" (1 lines)`,
+			},
+		},
+		{
+			name: "events",
+			index: `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"project","kind":"go"}
+{"id":3,"type":"vertex","label":"$event","kind":"begin","scope":"project","data":2}
+{"id":4,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":5,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":4}
+{"id":6,"type":"vertex","label":"range","start":{"line":1,"character":0},"end":{"line":1,"character":4}}
+{"id":7,"type":"vertex","label":"$event","kind":"end","scope":"project","data":2}
+{"id":8,"type":"vertex","label":"$event","kind":"end","scope":"document","data":4}
+{"id":9,"type":"edge","label":"contains","outV":4,"inVs":[6]}
+{"id":10,"type":"edge","label":"contains","outV":2,"inVs":[4]}
+`,
+			expected: []string{
+				RuleEventBracketing + ":error: project 2 ends before document 4, which began within it (3 lines)",
+				RuleEventScope + ":error: contains edge of document 4 occurs outside of its scope (3 lines)",
+			},
+		},
+		{
+			name: "events with invalid ranges",
+			index: `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":2,"character":5},"end":{"line":1,"character":0}}
+{"id":4,"type":"vertex","label":"$event","kind":"begin","scope":"document","data":2}
+{"id":5,"type":"vertex","label":"range","start":{"line":1,"character":0},"end":{"line":1,"character":4}}
+{"id":6,"type":"edge","label":"contains","outV":2,"inVs":[3,5]}
+{"id":7,"type":"vertex","label":"$event","kind":"end","scope":"document","data":2}
+`,
+			expected: []string{
+				RuleRangeVertex + ":error: illegal range extents (1 lines)",
+			},
+		},
+		{
+			name: "projects",
+			index: `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"project","kind":"go","name":"a"}
+{"id":3,"type":"vertex","label":"project","kind":"go","name":"b"}
+{"id":4,"type":"vertex","label":"document","uri":"file:///project/a.go"}
+{"id":5,"type":"vertex","label":"document","uri":"file:///project/b.go"}
+{"id":6,"type":"edge","label":"contains","outV":2,"inVs":[4]}
+{"id":7,"type":"edge","label":"contains","outV":3,"inVs":[4]}
+`,
+			expected: []string{
+				RuleDocumentOwnership + ":error: document 5 not owned by any project (1 lines)",
+				RuleUniqueDocumentOwnership + ":error: document 4 already claimed by project 2 (2 lines)",
+			},
+		},
+		{
+			name: "result set chains",
+			index: `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"vertex","label":"range","start":{"line":2,"character":1},"end":{"line":2,"character":5}}
+{"id":5,"type":"edge","label":"contains","outV":2,"inVs":[3,4]}
+{"id":6,"type":"vertex","label":"resultSet"}
+{"id":7,"type":"vertex","label":"resultSet"}
+{"id":8,"type":"edge","label":"next","outV":3,"inV":6}
+{"id":9,"type":"edge","label":"next","outV":6,"inV":7}
+{"id":10,"type":"edge","label":"next","outV":7,"inV":6}
+{"id":11,"type":"vertex","label":"resultSet"}
+{"id":12,"type":"edge","label":"next","outV":4,"inV":11}
+{"id":13,"type":"edge","label":"next","outV":4,"inV":6}
+{"id":14,"type":"vertex","label":"hoverResult","result":{"contents":"a"}}
+{"id":15,"type":"vertex","label":"hoverResult","result":{"contents":"b"}}
+{"id":16,"type":"edge","label":"textDocument/hover","outV":7,"inV":14}
+{"id":17,"type":"edge","label":"textDocument/hover","outV":7,"inV":15}
+`,
+			expected: []string{
+				RuleSingleNextEdge + ":error: range 4 has 2 next edges (3 lines)",
+				RuleNextAcyclic + ":error: next edges form a cycle through elements 6, 7 (6 lines)",
+				RuleSingleResultEdges + ":error: resultSet 7 has 2 textDocument/hover edges (7 lines)",
+			},
+		},
+		{
+			name: "definitions and references",
+			index: `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///project"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///project/main.go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":1,"character":1},"end":{"line":1,"character":5}}
+{"id":4,"type":"vertex","label":"range","start":{"line":2,"character":1},"end":{"line":2,"character":5}}
+{"id":5,"type":"vertex","label":"range","start":{"line":3,"character":1},"end":{"line":3,"character":5}}
+{"id":6,"type":"edge","label":"contains","outV":2,"inVs":[3,4,5]}
+{"id":7,"type":"vertex","label":"resultSet"}
+{"id":8,"type":"edge","label":"next","outV":3,"inV":7}
+{"id":9,"type":"edge","label":"next","outV":4,"inV":7}
+{"id":10,"type":"vertex","label":"definitionResult"}
+{"id":11,"type":"edge","label":"textDocument/definition","outV":7,"inV":10}
+{"id":12,"type":"edge","label":"item","outV":10,"inVs":[3,5],"document":2}
+{"id":13,"type":"vertex","label":"referenceResult"}
+{"id":14,"type":"edge","label":"textDocument/references","outV":7,"inV":13}
+{"id":15,"type":"edge","label":"item","outV":13,"inVs":[3],"document":2,"property":"definitions"}
+{"id":16,"type":"edge","label":"item","outV":13,"inVs":[4],"property":"references"}
+`,
+			expected: []string{
+				RuleItemEdge + ":error: missing required properties: document (1 lines)",
+				RuleDefinitionResolution + ":error: range 5 is listed by definitionResult 10 but cannot reach a definitionResult through its next chain (2 lines)",
+				RuleReferenceDefinitions + ":error: referenceResult 13 does not list range 5 of definitionResult 10 as a definition (3 lines)",
+			},
+		},
+		{
+			name:  "linked reference results",
+			index: linkedReferenceResults,
+		},
+		{
+			name:       "linked reference results without reference definitions",
+			index:      linkedReferenceResults,
+			severities: map[string]Severity{RuleReferenceDefinitions: SeverityOff},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			expected := append([]string(nil), testCase.expected...)
+			sort.Strings(expected)
+
+			if messages := validateMessages(t, testCase.index, testCase.severities); !reflect.DeepEqual(messages, expected) {
+				t.Errorf("unexpected errors: want %v, have %v", expected, messages)
+			}
+		})
+	}
+}
+
+// validateMessages validates the given index both in memory and disk-backed with the given
+// severities and returns the rule, severity, message, and number of relevant lines of each
+// error, sorted. The test fails if the two validations report different errors.
+func validateMessages(t *testing.T, index string, severities map[string]Severity) []string {
+	var reports [][]string
+	for _, diskBacked := range []bool{false, true} {
+		report, err := Validate(strings.NewReader(index), Options{Severities: severities, DiskBacked: diskBacked})
+		if err != nil {
+			t.Fatalf("unexpected error validating index: %s", err)
+		}
+
+		var messages []string
+		for _, err := range report.Errors {
+			messages = append(messages, fmt.Sprintf("%s:%s: %s (%d lines)", err.Rule, err.Severity, err.Message, len(err.RelevantLines)))
+		}
+		sort.Strings(messages)
+		reports = append(reports, messages)
+	}
+
+	if !reflect.DeepEqual(reports[0], reports[1]) {
+		t.Fatalf("disk-backed errors differ from in-memory errors: %v vs %v", reports[0], reports[1])
+	}
+
+	return reports[0]
+}
@@ -0,0 +1,149 @@
+package validation
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+type Validator struct {
+	Context *ValidationContext
+
+	// Registry holds the validators that are invoked. If nil, only the built-in validators
+	// are invoked.
+	Registry *Registry
+
+	raisedMissingMetadataError bool
+
+	// firstIndex is the index of the first non-empty line of the index file.
+	firstIndex int
+}
+
+func (v *Validator) Validate(indexFile io.Reader) error {
+	if err := reader2.Read(indexFile, v.Context.Stasher, v.vertexMapper, v.edgeMapper, v.errorMapper); err != nil {
+		return err
+	}
+
+	// The graph index and the ownership map are shared by the relationship validators. They are
+	// built before the validators run, as building the ownership map raises errors and excludes
+	// ranges claimed by multiple documents from the remainder of relationship validation.
+	_ = v.Context.Graph()
+	v.applyRule(RuleUniqueRangeOwnership, func() { _ = v.Context.OwnershipMap() })
+
+	// Relationship validators run even if some elements are invalid. Elements that failed
+	// element validation are excluded from this phase so that they cannot cause spurious
+	// errors (or worse) in validators that rely on well-formed elements. The built-in
+	// validators are independent and run concurrently; each attributes its errors to its
+	// own rule. Other validators run afterwards, one at a time.
+	offset := v.Context.numErrors()
+
+	var wg sync.WaitGroup
+	for _, rv := range v.registry().relationshipValidators {
+		if !rv.concurrent || v.Context.Severity(rv.RuleID) == reader2.SeverityOff {
+			continue
+		}
+
+		wg.Add(1)
+		go func(validator RelationshipValidator) {
+			defer wg.Done()
+			_ = validator(v.Context)
+		}(rv.Validator)
+	}
+	wg.Wait()
+
+	v.Context.tagErrors(offset, "")
+
+	for _, rv := range v.registry().relationshipValidators {
+		if rv.concurrent || v.Context.Severity(rv.RuleID) == reader2.SeverityOff {
+			continue
+		}
+
+		v.applyRule(rv.RuleID, func() { _ = rv.Validator(v.Context) })
+	}
+	v.Context.sortErrors()
+	return nil
+}
+
+func (v *Validator) vertexMapper(lineContext reader2.LineContext) {
+	atomic.AddUint64(&v.Context.NumVertices, 1)
+	v.observeLine(lineContext.Index)
+
+	if v.Context.ProjectRoot() == nil && !v.raisedMissingMetadataError && lineContext.Index != v.firstIndex {
+		v.raisedMissingMetadataError = true
+		v.applyRule(RuleMetaDataFirst, func() {
+			v.Context.AddError("metaData vertex must be defined on the first line").AddContext(lineContext)
+		})
+	}
+
+	// Element validators run even when their rule is disabled, as they may record state
+	// (such as the project root) that is required by other validators.
+	registry := v.registry()
+	v.applyElementRules(lineContext, registry.schemaVertexRules(lineContext.Element.Label), registry.vertexValidators[lineContext.Element.Label])
+}
+
+func (v *Validator) edgeMapper(lineContext reader2.LineContext) {
+	atomic.AddUint64(&v.Context.NumEdges, 1)
+	v.observeLine(lineContext.Index)
+
+	if v.Context.ProjectRoot() == nil && !v.raisedMissingMetadataError {
+		v.raisedMissingMetadataError = true
+		v.applyRule(RuleMetaDataFirst, func() {
+			v.Context.AddError("metaData vertex must be defined on the first line").AddContext(lineContext)
+		})
+	}
+
+	registry := v.registry()
+	v.applyElementRules(lineContext, registry.schemaEdgeRules(lineContext.Element.Label), registry.edgeValidators[lineContext.Element.Label])
+}
+
+func (v *Validator) errorMapper(err *reader2.ValidationError) {
+	for _, lineContext := range err.RelevantLines {
+		v.observeLine(lineContext.Index)
+	}
+
+	v.applyRule(RuleMalformedLine, func() { v.Context.addError(err) })
+}
+
+// observeLine records the index of a non-empty line of the index file. The mappers are invoked
+// in input order, so the first recorded index is that of the first non-empty line.
+func (v *Validator) observeLine(index int) {
+	if v.firstIndex == 0 {
+		v.firstIndex = index
+	}
+}
+
+// applyElementRules invokes each of the given element validators on the given element. The
+// element is excluded from relationship validation if any validator raises an error with error
+// severity.
+func (v *Validator) applyElementRules(lineContext reader2.LineContext, ruleSets ...[]elementRule) {
+	numFailures := 0
+	for _, rules := range ruleSets {
+		for _, rule := range rules {
+			numFailures += v.applyRule(rule.RuleID, func() { _ = rule.Validator(v.Context, lineContext) })
+		}
+	}
+
+	if numFailures > 0 {
+		v.Context.markInvalid(lineContext.Element.ID)
+	}
+}
+
+// registry returns the validator's registry, or the registry of built-in validators if none
+// was supplied.
+func (v *Validator) registry() *Registry {
+	if v.Registry != nil {
+		return v.Registry
+	}
+
+	return defaultRegistry
+}
+
+// applyRule invokes the given function and attributes the errors it raises to the given rule.
+// This method returns the number of raised errors with error severity.
+func (v *Validator) applyRule(ruleID string, f func()) int {
+	offset := v.Context.numErrors()
+	f()
+	return v.Context.tagErrors(offset, ruleID)
+}
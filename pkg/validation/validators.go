@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"sort"
+
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// ElementValidator validates specific properties of a single vertex or edge element.
+type ElementValidator func(ctx *ValidationContext, lineContext reader2.LineContext) bool
+
+// elementRule pairs an ElementValidator with the identifier of the rule it checks.
+type elementRule struct {
+	RuleID    string
+	Validator ElementValidator
+}
+
+// vertexValidators is a map from vertex labels to that vertex type's validator. The labels of
+// edges (and the properties required of each element) are checked as declared by the schema.
+var vertexValidators = map[string][]elementRule{
+	"metaData":           {{RuleMetaDataVertex, validateMetaDataVertex}},
+	"project":            {{RuleProjectVertex, validateProjectVertex}},
+	"document":           {{RuleDocumentVertex, validateDocumentVertex}},
+	"range":              {{RuleRangeVertex, validateRangeVertex}},
+	"moniker":            {{RuleMonikerVertex, validateMonikerVertex}},
+	"packageInformation": {{RulePackageInformationVertex, validatePackageInformationVertex}},
+	"diagnosticResult":   {{RuleDiagnosticResultVertex, validateDiagnosticResultVertex}},
+	"$event":             {{RuleEventVertex, validateEventVertex}},
+	"hoverResult":        {{RuleHoverResultVertex, validateHoverResultVertex}, {RuleEmptyHover, validateNonEmptyHover}},
+}
+
+// RelationshipValidator validates a specific property across all vertex and edges
+// registered to the given context's stasher.
+type RelationshipValidator func(ctx *ValidationContext) bool
+
+// relationshipRule pairs a RelationshipValidator with the identifier of the rule it checks.
+// Concurrent validators attribute their errors to their rule themselves and may run at the same
+// time as other concurrent validators.
+type relationshipRule struct {
+	RuleID     string
+	Validator  RelationshipValidator
+	concurrent bool
+}
+
+// relationshipValidators is the set of validators that operate across the entire LSIF graph.
+var relationshipValidators = []relationshipRule{
+	{RuleID: RuleReachability, Validator: ensureReachability},
+	{RuleID: RuleRangeOwnership, Validator: ensureRangeOwnership},
+	{RuleID: RuleDocumentOwnership, Validator: ensureDocumentOwnership},
+	{RuleID: RuleUniqueDocumentOwnership, Validator: ensureUniqueDocumentOwnership},
+	{RuleID: RuleDisjointRanges, Validator: ensureDisjointRanges},
+	{RuleID: RuleItemContains, Validator: ensureItemContains},
+	{RuleID: RuleNextMonikerAcyclic, Validator: ensureNextMonikerAcyclic},
+	{RuleID: RuleExportMonikerPackage, Validator: ensureExportMonikerPackage},
+	{RuleID: RuleSingleNextEdge, Validator: ensureSingleNextEdge},
+	{RuleID: RuleNextAcyclic, Validator: ensureNextAcyclic},
+	{RuleID: RuleSingleResultEdges, Validator: ensureSingleResultEdges},
+	{RuleID: RuleDefinitionResolution, Validator: ensureDefinitionResolution},
+	{RuleID: RuleReferenceDefinitions, Validator: ensureReferenceDefinitions},
+	{RuleID: RuleEventBracketing, Validator: ensureEventBracketing},
+	{RuleID: RuleEventScope, Validator: ensureEventScope},
+}
+
+// RuleIDs returns the identifier of every built-in rule that can be attached to a validation
+// error, ordered by identifier.
+func RuleIDs() []string {
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
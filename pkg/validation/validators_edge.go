@@ -0,0 +1,109 @@
+package validation
+
+import (
+	"strings"
+
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// OutValidator is the type of function that is invoked to validate the source vertex of an edge,
+// given its identifier and label.
+type OutValidator func(ctx *ValidationContext, edgeContext reader2.LineContext, outV int, outLabel string) bool
+
+// InValidator is the type of function that is invoked to validate the sink vertex of an edge,
+// given the label of the source vertex and the identifier and label of the sink vertex.
+type InValidator func(ctx *ValidationContext, edgeContext reader2.LineContext, outLabel string, inV int, inLabel string) bool
+
+// validateEdge validates the source and sink vertices of the given edge by invoking the given out and
+// in validators. This also ensures that there is at least one sink vertex attached to each edge, and
+// if a document property is present that it refers to a known document vertex.
+func validateEdge(ctx *ValidationContext, lineContext reader2.LineContext, outValidator OutValidator, inValidator InValidator) bool {
+	edge, ok := lineContext.Element.Payload.(reader.Edge)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	outLabel, ok := validateOutV(ctx, lineContext, edge, outValidator)
+	if !ok {
+		return false
+	}
+
+	if !validateInVs(ctx, lineContext, outLabel, edge, inValidator) {
+		return false
+	}
+
+	if !validateEdgeDocument(ctx, lineContext, edge) {
+		return false
+	}
+
+	return true
+}
+
+// validateOutV validates the OutV property of the given edge. This returns the label of the
+// source vertex.
+func validateOutV(ctx *ValidationContext, lineContext reader2.LineContext, edge reader.Edge, outValidator OutValidator) (string, bool) {
+	outLabel, ok := ctx.Stasher.VertexLabel(edge.OutV)
+	if !ok {
+		ctx.AddError("no such vertex %d", edge.OutV).AddContext(lineContext)
+		return "", false
+	}
+
+	return outLabel, outValidator == nil || outValidator(ctx, lineContext, edge.OutV, outLabel)
+}
+
+// validateInVs validates the InV/InVs properties of the given edge.
+func validateInVs(ctx *ValidationContext, lineContext reader2.LineContext, outLabel string, edge reader.Edge, inValidator InValidator) bool {
+	if !reader2.ForEachInV(edge, func(inV int) bool {
+		inLabel, ok := ctx.Stasher.VertexLabel(inV)
+		if !ok {
+			ctx.AddError("no such vertex %d", inV).AddContext(lineContext)
+			return false
+		}
+
+		return inValidator == nil || inValidator(ctx, lineContext, outLabel, inV, inLabel)
+	}) {
+		return false
+	}
+
+	if edge.InV == 0 && len(edge.InVs) == 0 {
+		ctx.AddError("no InVs are specified").AddContext(lineContext)
+		return false
+	}
+
+	return true
+}
+
+// validateEdgeDocument validates the document property of the given edge.
+func validateEdgeDocument(ctx *ValidationContext, lineContext reader2.LineContext, edge reader.Edge) bool {
+	if edge.Document == 0 {
+		return true
+	}
+
+	documentLabel, ok := ctx.Stasher.VertexLabel(edge.Document)
+	if !ok {
+		ctx.AddError("no such vertex %d", edge.Document).AddContext(lineContext)
+		return false
+	}
+	if !validateLabels(ctx, lineContext, edge.Document, documentLabel, []string{"document"}) {
+		return false
+	}
+
+	return true
+}
+
+// validateLabels marks an error and returns false if the given adjacent vertex label is not one of the given
+// labels. The error will contain the given lineContext, which is meant to represent the edge that dictates the
+// relationship between its adjacent vertices.
+func validateLabels(ctx *ValidationContext, lineContext reader2.LineContext, adjacentID int, adjacentLabel string, labels []string) bool {
+	for _, label := range labels {
+		if adjacentLabel == label {
+			return true
+		}
+	}
+
+	types := strings.Join(labels, ", ")
+	ctx.AddError("expected vertex %d to be of type %s", adjacentID, types).AddContext(ctx.vertexContext(adjacentID), lineContext)
+	return false
+}
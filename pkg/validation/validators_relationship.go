@@ -0,0 +1,788 @@
+package validation
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// DefaultReachabilityIgnoreList is the default set of vertex labels that are exempt from the
+// reachability check.
+var DefaultReachabilityIgnoreList = []string{"metaData", "project", "document", "$event"}
+
+// ensureReachability ensures that every vertex (except for those with a label in the context's
+// reachability ignore list) is reachable by tracing the forward edges starting at the set of range
+// vertices and the document that contains them, or at a project.
+func ensureReachability(ctx *ValidationContext) bool {
+	visited := traverseGraph(ctx)
+	errs := newErrorAggregator(ctx, RuleReachability, "unreachable vertices")
+
+	_ = ctx.vertexLabels(func(id int, label string) bool {
+		for _, ignoredLabel := range ctx.ReachabilityIgnoreList {
+			if label == ignoredLabel {
+				return true
+			}
+		}
+
+		if _, ok := visited[id]; !ok {
+			errs.add(label, []reader2.LineContext{ctx.vertexContext(id)}, "vertex %d unreachable from any range", id)
+		}
+
+		return true
+	})
+
+	return errs.flush()
+}
+
+// traverseGraph returns a set of vertex identifiers which are reachable by tracing the forward edges
+// of the graph starting from the set of project vertices and the set of contains edges between
+// documents and ranges.
+func traverseGraph(ctx *ValidationContext) map[int]struct{} {
+	frontier := ctx.verticesWithLabel("project")
+	_ = ctx.edgesWithLabel("contains", func(id int, edge reader.Edge) bool {
+		if outLabel, ok := ctx.vertexLabel(edge.OutV); ok && outLabel == "document" {
+			frontier = append(append(frontier, edge.OutV), reader2.EachInV(edge)...)
+		}
+
+		return true
+	})
+
+	visited := map[int]struct{}{}
+
+	for len(frontier) > 0 {
+		var top int
+		top, frontier = frontier[0], frontier[1:]
+		if _, ok := visited[top]; ok {
+			continue
+		}
+
+		visited[top] = struct{}{}
+		frontier = append(frontier, ctx.outNeighbors(top)...)
+	}
+
+	return visited
+}
+
+// ensureRangeOwnership ensures that every range vertex is adjacent to a contains
+// edge to some document.
+func ensureRangeOwnership(ctx *ValidationContext) bool {
+	ownershipMap := ctx.OwnershipMap()
+	errs := newErrorAggregator(ctx, RuleRangeOwnership, "ranges not owned by any document")
+
+	for _, id := range ctx.verticesWithLabel("range") {
+		if _, ok := ownershipMap[id]; !ok {
+			errs.add("range", []reader2.LineContext{ctx.vertexContext(id)}, "range %d not owned by any document", id)
+		}
+	}
+
+	return errs.flush()
+}
+
+// ensureDocumentOwnership ensures that every document vertex is adjacent to a contains edge to
+// some project, if the index has any project vertices.
+func ensureDocumentOwnership(ctx *ValidationContext) bool {
+	if len(ctx.verticesWithLabel("project")) == 0 {
+		return true
+	}
+
+	ownershipMap := ctx.ProjectOwnershipMap()
+	errs := newErrorAggregator(ctx, RuleDocumentOwnership, "documents not owned by any project")
+
+	for _, id := range ctx.verticesWithLabel("document") {
+		if _, ok := ownershipMap[id]; !ok {
+			errs.add("document", []reader2.LineContext{ctx.vertexContext(id)}, "document %d not owned by any project", id)
+		}
+	}
+
+	return errs.flush()
+}
+
+// ensureUniqueDocumentOwnership ensures that no document vertex is adjacent to contains edges
+// from more than one project.
+func ensureUniqueDocumentOwnership(ctx *ValidationContext) bool {
+	ownershipMap := ctx.ProjectOwnershipMap()
+	errs := newErrorAggregator(ctx, RuleUniqueDocumentOwnership, "documents owned by multiple projects")
+
+	_ = ctx.edgesWithLabel("contains", func(id int, edge reader.Edge) bool {
+		if outLabel, ok := ctx.vertexLabel(edge.OutV); !ok || outLabel != "project" {
+			return true
+		}
+
+		return reader2.ForEachInV(edge, func(inV int) bool {
+			if other, ok := ownershipMap[inV]; ok && other.OwnerID != edge.OutV {
+				lineContexts := []reader2.LineContext{ctx.edgeContext(id), ctx.edgeContext(other.EdgeID)}
+				errs.add(documentLabel(ctx, inV), lineContexts, "document %d already claimed by project %d", inV, other.OwnerID)
+			}
+
+			return true
+		})
+	})
+
+	return errs.flush()
+}
+
+// ensureDisjointRanges ensures that the set of ranges within a single document are either
+// properly nested or completely disjoint. Documents are checked concurrently, but overlapping
+// ranges are recorded in document order so that the reported errors do not depend on scheduling.
+func ensureDisjointRanges(ctx *ValidationContext) bool {
+	ownershipMap := ctx.OwnershipMap()
+	errs := newErrorAggregator(ctx, RuleDisjointRanges, "overlapping ranges")
+
+	documentIDs := ctx.verticesWithLabel("document")
+	sort.Ints(documentIDs)
+
+	overlaps := make([][][2]int, len(documentIDs))
+	parallelize(len(documentIDs), func(i int) {
+		rangeIDs := documentRanges(ctx, ownershipMap, documentIDs[i])
+		if len(rangeIDs) == 0 {
+			return
+		}
+
+		ranges := make([]rangeVertex, 0, len(rangeIDs))
+		for _, rangeID := range rangeIDs {
+			if r, ok := ctx.rangeBounds(rangeID); ok {
+				ranges = append(ranges, rangeVertex{id: rangeID, bounds: r})
+			}
+		}
+
+		overlaps[i] = findOverlaps(ranges)
+	})
+
+	for i, documentID := range documentIDs {
+		for _, pair := range overlaps[i] {
+			lineContexts := []reader2.LineContext{ctx.vertexContext(pair[0]), ctx.vertexContext(pair[1])}
+			errs.add(documentLabel(ctx, documentID), lineContexts, "ranges overlap in document %d", documentID)
+		}
+	}
+
+	return errs.flush()
+}
+
+// rangeVertex bundles the identifier and bounds of a range vertex.
+type rangeVertex struct {
+	id     int
+	bounds reader.Range
+}
+
+// findOverlaps returns the identifiers of each pair from the set of ranges which overlap but are
+// not properly nested within one another.
+func findOverlaps(ranges []rangeVertex) [][2]int {
+	sort.SliceStable(ranges, func(i, j int) bool {
+		r1 := ranges[i].bounds
+		r2 := ranges[j].bounds
+
+		// Sort by starting offset (if on the same line, break ties by start character)
+		return r1.StartLine < r2.StartLine || (r1.StartLine == r2.StartLine && r1.StartCharacter < r2.StartCharacter)
+	})
+
+	var overlaps [][2]int
+	for i := 1; i < len(ranges); i++ {
+		r1 := ranges[i-1].bounds
+		r2 := ranges[i].bounds
+
+		// r1 ends after r2, so r1 properly encloses r2
+		if r1.EndLine > r2.EndLine || (r1.EndLine == r2.EndLine && r1.EndCharacter >= r2.EndCharacter) {
+			continue
+		}
+
+		// r1 ends before r2 starts so they are disjoint
+		if r1.EndLine < r2.StartLine || (r1.EndLine == r2.StartLine && r1.EndCharacter < r2.StartCharacter) {
+			continue
+		}
+
+		overlaps = append(overlaps, [2]int{ranges[i-1].id, ranges[i].id})
+	}
+
+	return overlaps
+}
+
+// documentLabel returns the URI of the document with the given identifier, or its identifier if
+// the document is not known.
+func documentLabel(ctx *ValidationContext, documentID int) string {
+	if lineContext, ok := ctx.Stasher.Vertex(documentID); ok {
+		if uri, ok := lineContext.Element.Payload.(string); ok {
+			return uri
+		}
+	}
+
+	return fmt.Sprintf("document %d", documentID)
+}
+
+// ensureItemContains ensures that the inVs of every item edge refer to range that belong
+// to the document specified by the item edge's document property. Item edges that link a
+// referenceResult to other referenceResults are exempt.
+func ensureItemContains(ctx *ValidationContext) bool {
+	ownershipMap := ctx.OwnershipMap()
+	errs := newErrorAggregator(ctx, RuleItemContains, "item edges referring to ranges of another document")
+
+	_ = ctx.edgesWithLabel("item", func(id int, edge reader.Edge) bool {
+		if ctx.itemProperty(id) == "referenceResults" {
+			return true
+		}
+
+		return reader2.ForEachInV(edge, func(inV int) bool {
+			if !ctx.isValid(inV) {
+				return true
+			}
+
+			if ownershipContext, ok := ownershipMap[inV]; ownershipContext.OwnerID != edge.Document {
+				lineContexts := []reader2.LineContext{ctx.edgeContext(id)}
+				if ok {
+					lineContexts = append(lineContexts, ctx.edgeContext(ownershipContext.EdgeID))
+				}
+
+				errs.add(documentLabel(ctx, edge.Document), lineContexts, "vertex should be %d owned by document %d", inV, edge.Document)
+			}
+
+			return true
+		})
+	})
+
+	return errs.flush()
+}
+
+// ensureNextMonikerAcyclic ensures that no moniker can be reached from itself by following
+// nextMoniker edges.
+func ensureNextMonikerAcyclic(ctx *ValidationContext) bool {
+	nextMonikers := outEdgesWithLabel(ctx, "nextMoniker")
+	errs := newErrorAggregator(ctx, RuleNextMonikerAcyclic, "nextMoniker cycles")
+
+	monikerIDs := make([]int, 0, len(nextMonikers))
+	for id := range nextMonikers {
+		monikerIDs = append(monikerIDs, id)
+	}
+	sort.Ints(monikerIDs)
+
+	// pathFrame is a moniker on the current path of the traversal, along with the edge that
+	// was followed to reach it and the index of its next out edge to follow.
+	type pathFrame struct {
+		id     int
+		edgeID int
+		next   int
+	}
+
+	const (
+		onPath = iota + 1
+		finished
+	)
+
+	state := map[int]int{}
+	for _, root := range monikerIDs {
+		if state[root] != 0 {
+			continue
+		}
+
+		state[root] = onPath
+		path := []pathFrame{{id: root}}
+
+		for len(path) > 0 {
+			top := &path[len(path)-1]
+			if top.next == len(nextMonikers[top.id]) {
+				state[top.id] = finished
+				path = path[:len(path)-1]
+				continue
+			}
+
+			edge := nextMonikers[top.id][top.next]
+			top.next++
+
+			switch state[edge.inV] {
+			case 0:
+				state[edge.inV] = onPath
+				path = append(path, pathFrame{id: edge.inV, edgeID: edge.id})
+
+			case onPath:
+				// The edge closes a cycle through the monikers on the path from its inV
+				start := len(path) - 1
+				for path[start].id != edge.inV {
+					start--
+				}
+
+				var lineContexts []reader2.LineContext
+				ids := make([]string, 0, len(path)-start)
+				for i, frame := range path[start:] {
+					if i > 0 {
+						lineContexts = append(lineContexts, ctx.edgeContext(frame.edgeID))
+					}
+					ids = append(ids, strconv.Itoa(frame.id))
+				}
+				lineContexts = append(lineContexts, ctx.edgeContext(edge.id))
+
+				errs.add("moniker", lineContexts, "nextMoniker edges form a cycle through monikers %s", strings.Join(ids, ", "))
+			}
+		}
+	}
+
+	return errs.flush()
+}
+
+// ensureExportMonikerPackage ensures that every export moniker is attached to a packageInformation
+// vertex, either directly or through the monikers reachable by following its nextMoniker edges.
+func ensureExportMonikerPackage(ctx *ValidationContext) bool {
+	nextMonikers := outEdgesWithLabel(ctx, "nextMoniker")
+	errs := newErrorAggregator(ctx, RuleExportMonikerPackage, "export monikers without package information")
+
+	hasPackageInformation := map[int]bool{}
+	_ = ctx.edgesWithLabel("packageInformation", func(id int, edge reader.Edge) bool {
+		return reader2.ForEachInV(edge, func(inV int) bool {
+			if label, ok := ctx.vertexLabel(inV); ok && label == "packageInformation" {
+				hasPackageInformation[edge.OutV] = true
+			}
+
+			return true
+		})
+	})
+
+	for _, id := range ctx.verticesWithLabel("moniker") {
+		lineContext, ok := ctx.Stasher.Vertex(id)
+		if !ok {
+			continue
+		}
+
+		moniker, ok := lineContext.Element.Payload.(reader.Moniker)
+		if !ok || moniker.Kind != "export" {
+			continue
+		}
+
+		if !reachesPackageInformation(id, nextMonikers, hasPackageInformation) {
+			errs.add(moniker.Scheme, []reader2.LineContext{lineContext}, "export moniker %d has no package information", id)
+		}
+	}
+
+	return errs.flush()
+}
+
+// reachesPackageInformation returns true if the given moniker, or any moniker reachable from it
+// by following nextMoniker edges, is attached to a packageInformation vertex.
+func reachesPackageInformation(id int, nextMonikers map[int][]outEdge, hasPackageInformation map[int]bool) bool {
+	visited := map[int]struct{}{}
+
+	for frontier := []int{id}; len(frontier) > 0; {
+		var top int
+		top, frontier = frontier[0], frontier[1:]
+		if _, ok := visited[top]; ok {
+			continue
+		}
+
+		if hasPackageInformation[top] {
+			return true
+		}
+
+		visited[top] = struct{}{}
+		for _, edge := range nextMonikers[top] {
+			frontier = append(frontier, edge.inV)
+		}
+	}
+
+	return false
+}
+
+// outEdge bundles the identifier and an inV of an edge.
+type outEdge struct {
+	id  int
+	inV int
+}
+
+// outEdgesWithLabel returns a map from vertex identifiers to the edges with the given label out
+// of that vertex, in the order in which the edges were registered. An edge with multiple inVs is
+// listed once for each inV.
+func outEdgesWithLabel(ctx *ValidationContext, label string) map[int][]outEdge {
+	outEdges := map[int][]outEdge{}
+	_ = ctx.edgesWithLabel(label, func(id int, edge reader.Edge) bool {
+		return reader2.ForEachInV(edge, func(inV int) bool {
+			outEdges[edge.OutV] = append(outEdges[edge.OutV], outEdge{id: id, inV: inV})
+			return true
+		})
+	})
+
+	return outEdges
+}
+
+// ensureSingleNextEdge ensures that each range and result set has at most one next edge. Each
+// error lists the line contexts of the chain from its first element up to the violation.
+func ensureSingleNextEdge(ctx *ValidationContext) bool {
+	errs := newErrorAggregator(ctx, RuleSingleNextEdge, "elements with multiple next edges")
+
+	ctx.resultSetChains().walk(ctx, func(element chainElement) {
+		if len(element.next) > 1 {
+			errs.add(element.label, appendEdgeContexts(ctx, element.path, element.next), "%s %d has %d next edges", element.label, element.id, len(element.next))
+		}
+	})
+
+	return errs.flush()
+}
+
+// ensureNextAcyclic ensures that no chain of next edges loops back on itself. Each error lists the
+// line contexts of the chain from its first element around the cycle.
+func ensureNextAcyclic(ctx *ValidationContext) bool {
+	errs := newErrorAggregator(ctx, RuleNextAcyclic, "next cycles")
+
+	ctx.resultSetChains().walk(ctx, func(element chainElement) {
+		if element.cycle == nil {
+			return
+		}
+
+		cycle := make([]string, 0, len(element.cycle))
+		for _, id := range element.cycle {
+			cycle = append(cycle, strconv.Itoa(id))
+		}
+
+		errs.add("next", appendEdgeContexts(ctx, element.path, element.next), "next edges form a cycle through elements %s", strings.Join(cycle, ", "))
+	})
+
+	return errs.flush()
+}
+
+// ensureSingleResultEdges ensures that each element of a result set chain has at most one
+// definition, references, and hover edge. Each error lists the line contexts of the chain from
+// its first element up to the violation.
+func ensureSingleResultEdges(ctx *ValidationContext) bool {
+	errs := newErrorAggregator(ctx, RuleSingleResultEdges, "elements with multiple result edges")
+	chains := ctx.resultSetChains()
+
+	chains.walk(ctx, func(element chainElement) {
+		for _, label := range chainResultLabels {
+			if edges := chains.resultEdges[label][element.id]; len(edges) > 1 {
+				errs.add(label, appendEdgeContexts(ctx, element.path, edges), "%s %d has %d %s edges", element.label, element.id, len(edges), label)
+			}
+		}
+	})
+
+	return errs.flush()
+}
+
+// appendEdgeContexts returns a copy of the given line contexts followed by the line contexts of
+// the given edges.
+func appendEdgeContexts(ctx *ValidationContext, lineContexts []reader2.LineContext, edges []outEdge) []reader2.LineContext {
+	combined := make([]reader2.LineContext, 0, len(lineContexts)+len(edges))
+	combined = append(combined, lineContexts...)
+	for _, edge := range edges {
+		combined = append(combined, ctx.edgeContext(edge.id))
+	}
+
+	return combined
+}
+
+// ensureDefinitionResolution ensures that each range listed by a definitionResult resolves to
+// that definitionResult through its own chain of next edges.
+func ensureDefinitionResolution(ctx *ValidationContext) bool {
+	chains := ctx.resultSetChains()
+	errs := newErrorAggregator(ctx, RuleDefinitionResolution, "definition ranges that do not resolve to their definitionResult")
+
+	_ = ctx.edgesWithLabel("item", func(id int, edge reader.Edge) bool {
+		if label, ok := ctx.vertexLabel(edge.OutV); !ok || label != "definitionResult" {
+			return true
+		}
+
+		return reader2.ForEachInV(edge, func(inV int) bool {
+			if !ctx.isValid(inV) {
+				return true
+			}
+
+			definition, ok := chains.first(inV, "textDocument/definition")
+			if !ok {
+				errs.add("range", []reader2.LineContext{ctx.vertexContext(inV), ctx.edgeContext(id)}, "range %d is listed by definitionResult %d but cannot reach a definitionResult through its next chain", inV, edge.OutV)
+			} else if definition.inV != edge.OutV {
+				errs.add("range", []reader2.LineContext{ctx.vertexContext(inV), ctx.edgeContext(id), ctx.edgeContext(definition.id)}, "range %d is listed by definitionResult %d but resolves to definitionResult %d", inV, edge.OutV, definition.inV)
+			}
+
+			return true
+		})
+	})
+
+	return errs.flush()
+}
+
+// ensureReferenceDefinitions ensures that each referenceResult lists the ranges of the
+// definitionResult of the same range or result set as items with a "definitions" property. The
+// definitions listed by the referenceResults linked through items with a "referenceResults"
+// property count as definitions of the linking referenceResult.
+func ensureReferenceDefinitions(ctx *ValidationContext) bool {
+	chains := ctx.resultSetChains()
+	itemEdges := outEdgesWithLabel(ctx, "item")
+	errs := newErrorAggregator(ctx, RuleReferenceDefinitions, "definition ranges missing from referenceResults")
+
+	// definitions is a map from referenceResult identifiers to the set of ranges listed by
+	// that referenceResult as definitions, and links is a map from referenceResult identifiers
+	// to the referenceResults it links to
+	definitions := map[int]map[int]struct{}{}
+	links := map[int][]int{}
+	for outV, edges := range itemEdges {
+		for _, edge := range edges {
+			switch ctx.itemProperty(edge.id) {
+			case "definitions":
+				if _, ok := definitions[outV]; !ok {
+					definitions[outV] = map[int]struct{}{}
+				}
+				definitions[outV][edge.inV] = struct{}{}
+
+			case "referenceResults":
+				links[outV] = append(links[outV], edge.inV)
+			}
+		}
+	}
+
+	checked := map[[2]int]struct{}{}
+	_ = ctx.edgesWithLabel("textDocument/references", func(id int, edge reader.Edge) bool {
+		definition, ok := chains.first(edge.OutV, "textDocument/definition")
+		if !ok {
+			return true
+		}
+
+		return reader2.ForEachInV(edge, func(referenceResult int) bool {
+			if _, ok := checked[[2]int{referenceResult, definition.inV}]; ok {
+				return true
+			}
+			checked[[2]int{referenceResult, definition.inV}] = struct{}{}
+
+			for _, item := range itemEdges[definition.inV] {
+				if !listsDefinition(referenceResult, item.inV, definitions, links) {
+					lineContexts := []reader2.LineContext{ctx.edgeContext(id), ctx.edgeContext(definition.id), ctx.edgeContext(item.id)}
+					errs.add("referenceResult", lineContexts, "referenceResult %d does not list range %d of definitionResult %d as a definition", referenceResult, item.inV, definition.inV)
+				}
+			}
+
+			return true
+		})
+	})
+
+	return errs.flush()
+}
+
+// listsDefinition returns true if the given referenceResult, or any referenceResult reachable
+// from it by following links, lists the given range as a definition.
+func listsDefinition(referenceResult, rangeID int, definitions map[int]map[int]struct{}, links map[int][]int) bool {
+	visited := map[int]struct{}{}
+	frontier := []int{referenceResult}
+
+	for len(frontier) > 0 {
+		id := frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+
+		if _, ok := visited[id]; ok {
+			continue
+		}
+		visited[id] = struct{}{}
+
+		if _, ok := definitions[id][rangeID]; ok {
+			return true
+		}
+		frontier = append(frontier, links[id]...)
+	}
+
+	return false
+}
+
+// ensureEventBracketing ensures that each scope begun by a $event vertex is ended exactly once by
+// a later $event vertex, and that scopes are properly nested (the scopes begun within a scope
+// end before it ends).
+func ensureEventBracketing(ctx *ValidationContext) bool {
+	errs := newErrorAggregator(ctx, RuleEventBracketing, "unbalanced events")
+
+	begun := map[int]struct{}{}
+	var open []eventVertex
+
+	for _, e := range orderedEvents(ctx) {
+		switch e.event.Kind {
+		case "begin":
+			if _, ok := begun[e.event.Data]; ok {
+				errs.add(e.event.Scope, []reader2.LineContext{e.lineContext}, "%s %d begins more than once", e.event.Scope, e.event.Data)
+				continue
+			}
+
+			begun[e.event.Data] = struct{}{}
+			open = append(open, e)
+
+		case "end":
+			i := len(open) - 1
+			for i >= 0 && open[i].event.Data != e.event.Data {
+				i--
+			}
+
+			if i < 0 {
+				if _, ok := begun[e.event.Data]; ok {
+					errs.add(e.event.Scope, []reader2.LineContext{e.lineContext}, "%s %d ends more than once", e.event.Scope, e.event.Data)
+				} else {
+					errs.add(e.event.Scope, []reader2.LineContext{e.lineContext}, "%s %d ends without beginning", e.event.Scope, e.event.Data)
+				}
+
+				continue
+			}
+
+			if i < len(open)-1 {
+				inner := open[len(open)-1]
+				lineContexts := []reader2.LineContext{open[i].lineContext, inner.lineContext, e.lineContext}
+				errs.add(e.event.Scope, lineContexts, "%s %d ends before %s %d, which began within it", e.event.Scope, e.event.Data, inner.event.Scope, inner.event.Data)
+			}
+
+			open = append(open[:i], open[i+1:]...)
+		}
+	}
+
+	for _, e := range open {
+		errs.add(e.event.Scope, []reader2.LineContext{e.lineContext}, "%s %d begins without ending", e.event.Scope, e.event.Data)
+	}
+
+	return errs.flush()
+}
+
+// ensureEventScope ensures that the contains edges of each document with begin and end events,
+// and the ranges they contain, occur between those events, and that no other edge refers to the
+// document after its end event. Contains edges from a project are exempt, as they may be emitted
+// once the documents of the project have ended.
+func ensureEventScope(ctx *ValidationContext) bool {
+	errs := newErrorAggregator(ctx, RuleEventScope, "elements outside of the scope of their document")
+
+	type documentScope struct {
+		begin, end reader2.LineContext
+		hasBegin   bool
+		hasEnd     bool
+	}
+
+	scopes := map[int]*documentScope{}
+	for _, e := range orderedEvents(ctx) {
+		if e.event.Scope != "document" {
+			continue
+		}
+
+		scope, ok := scopes[e.event.Data]
+		if !ok {
+			scope = &documentScope{}
+			scopes[e.event.Data] = scope
+		}
+
+		if e.event.Kind == "begin" && !scope.hasBegin {
+			scope.begin, scope.hasBegin = e.lineContext, true
+		}
+		if e.event.Kind == "end" && !scope.hasEnd {
+			scope.end, scope.hasEnd = e.lineContext, true
+		}
+	}
+
+	itemEdges := map[int][]int{}
+	_ = ctx.edgesWithLabel("item", func(id int, edge reader.Edge) bool {
+		itemEdges[edge.Document] = append(itemEdges[edge.Document], id)
+		return true
+	})
+
+	documentIDs := make([]int, 0, len(scopes))
+	for documentID, scope := range scopes {
+		if scope.hasBegin && scope.hasEnd {
+			documentIDs = append(documentIDs, documentID)
+		}
+	}
+	sort.Ints(documentIDs)
+
+	graph := ctx.Graph()
+	for _, documentID := range documentIDs {
+		scope := scopes[documentID]
+		kind := documentLabel(ctx, documentID)
+		within := func(lineContext reader2.LineContext) bool {
+			return lineContext.Index > scope.begin.Index && lineContext.Index < scope.end.Index
+		}
+
+		var edgeIDs []int
+		edgeIDs = append(edgeIDs, graph.OutEdges(documentID)...)
+		edgeIDs = append(edgeIDs, graph.InEdges(documentID)...)
+		edgeIDs = append(edgeIDs, itemEdges[documentID]...)
+		sort.Ints(edgeIDs)
+
+		for i, edgeID := range edgeIDs {
+			if (i > 0 && edgeIDs[i-1] == edgeID) || !ctx.isValid(edgeID) {
+				continue
+			}
+
+			edgeContext := ctx.edgeContext(edgeID)
+			edge, _ := graph.Edge(edgeID)
+			outLabel, _ := ctx.vertexLabel(edge.OutV)
+
+			switch {
+			case edgeContext.Element.Label == "contains" && edge.OutV == documentID:
+				if !within(edgeContext) {
+					errs.add(kind, []reader2.LineContext{edgeContext, scope.begin, scope.end}, "contains edge of document %d occurs outside of its scope", documentID)
+					continue
+				}
+
+				_ = reader2.ForEachInV(edge, func(inV int) bool {
+					// Ranges that are missing or invalid have already been reported
+					if !ctx.isValid(inV) {
+						return true
+					}
+					rangeContext, ok := ctx.Stasher.Vertex(inV)
+					if !ok {
+						return true
+					}
+
+					if !within(rangeContext) {
+						errs.add(kind, []reader2.LineContext{rangeContext, scope.begin, scope.end}, "range %d of document %d occurs outside of its scope", inV, documentID)
+					}
+
+					return true
+				})
+
+			case edgeContext.Element.Label == "contains" && outLabel == "project":
+				// Projects may declare their documents after the documents have ended
+
+			case edgeContext.Index > scope.end.Index:
+				errs.add(kind, []reader2.LineContext{edgeContext, scope.end}, "edge refers to document %d after its end event", documentID)
+			}
+		}
+	}
+
+	return errs.flush()
+}
+
+// eventVertex bundles the line context and payload of a $event vertex.
+type eventVertex struct {
+	lineContext reader2.LineContext
+	event       reader2.Event
+}
+
+// orderedEvents returns the $event vertices that have not been marked invalid, in the order in
+// which they occur in the index.
+func orderedEvents(ctx *ValidationContext) []eventVertex {
+	var events []eventVertex
+	for _, id := range ctx.verticesWithLabel("$event") {
+		lineContext, ok := ctx.Stasher.Vertex(id)
+		if !ok {
+			continue
+		}
+
+		if event, ok := lineContext.Element.Payload.(reader2.Event); ok {
+			events = append(events, eventVertex{lineContext: lineContext, event: event})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].lineContext.Index < events[j].lineContext.Index
+	})
+
+	return events
+}
+
+// parallelize invokes the given function with each index in [0, n) from a pool of goroutines,
+// and returns once every invocation has completed.
+func parallelize(n int, f func(i int)) {
+	var next int64 = -1
+	var wg sync.WaitGroup
+
+	for w := 0; w < runtime.GOMAXPROCS(0) && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := int(atomic.AddInt64(&next, 1)); i < n; i = int(atomic.AddInt64(&next, 1)) {
+				f(i)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,293 @@
+package validation
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	reader "github.com/sourcegraph/lsif-protocol/reader"
+	reader2 "github.com/sourcegraph/lsif-test/internal/reader"
+)
+
+// validateMetaDataVertex ensures that the given metadata vertex has a valid project root. The
+// project root is stashed in the validation context for use by validateDocumentVertex.
+func validateMetaDataVertex(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	if ctx.ProjectRoot() != nil {
+		ctx.AddError("metaData defined multiple times").AddContext(lineContext)
+	}
+
+	metaData, ok := lineContext.Element.Payload.(reader.MetaData)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	url, err := url.Parse(metaData.ProjectRoot)
+	if err != nil {
+		ctx.AddError("project root is not a valid URL").AddContext(lineContext)
+		return false
+	}
+	if url.Scheme == "" {
+		ctx.AddError("project root is not a valid URL").AddContext(lineContext)
+		return false
+	}
+
+	ctx.setProjectRoot(url)
+	return true
+}
+
+// validateProjectVertex ensures that the given project vertex has a kind and, if it has a name
+// property, a non-empty name.
+func validateProjectVertex(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	project, ok := lineContext.Element.Payload.(reader2.Project)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	if project.Kind == "" {
+		ctx.AddError("project has an empty kind").AddContext(lineContext)
+		return false
+	}
+	if project.Name == "" && containsLabel(lineContext.Properties, "name") {
+		ctx.AddError("project has an empty name").AddContext(lineContext)
+		return false
+	}
+
+	return true
+}
+
+// validateDocumentVertex ensures that the given document vertex has a valid URI which is
+// relative to the project root. If the context has a source root, the document must also
+// refer to an existing file within it.
+func validateDocumentVertex(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	uri, ok := lineContext.Element.Payload.(string)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	url, err := url.Parse(uri)
+	if err != nil {
+		ctx.AddError("document uri is not a valid URL").AddContext(lineContext)
+		return false
+	}
+	if url.Scheme == "" {
+		ctx.AddError("document uri is not a valid URL").AddContext(lineContext)
+		return false
+	}
+
+	projectRoot := ctx.ProjectRoot()
+	if projectRoot != nil && !strings.HasPrefix(url.String(), projectRoot.String()) {
+		ctx.AddError("document is not relative to project root").AddContext(lineContext)
+		return false
+	}
+
+	if projectRoot != nil && ctx.SourceRoot != "" {
+		relativePath := strings.TrimPrefix(url.Path, projectRoot.Path)
+		if _, err := os.Stat(filepath.Join(ctx.SourceRoot, filepath.FromSlash(relativePath))); err != nil {
+			ctx.AddError("document does not exist in source root").AddContext(lineContext)
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateRangeVertex ensures that the given range vertex has valid bounds and extents.
+func validateRangeVertex(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	r, ok := lineContext.Element.Payload.(reader.Range)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	if message := checkRangeBounds(r); message != "" {
+		ctx.AddError(message).AddContext(lineContext)
+		return false
+	}
+
+	return true
+}
+
+// checkRangeBounds returns a description of the problem with the bounds of the given range, or
+// an empty string if the range has non-negative bounds and does not end before it starts.
+func checkRangeBounds(r reader.Range) string {
+	if r.StartLine < 0 || r.StartCharacter < 0 || r.EndLine < 0 || r.EndCharacter < 0 {
+		return "illegal range bounds"
+	}
+
+	if r.StartLine > r.EndLine {
+		return "illegal range extents"
+	}
+	if r.StartLine == r.EndLine && r.StartCharacter > r.EndCharacter {
+		return "illegal range extents"
+	}
+
+	return ""
+}
+
+// validateMonikerVertex ensures that the given moniker vertex has a scheme, an identifier, and
+// a legal kind.
+func validateMonikerVertex(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	moniker, ok := lineContext.Element.Payload.(reader.Moniker)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	if moniker.Scheme == "" {
+		ctx.AddError("moniker has an empty scheme").AddContext(lineContext)
+		return false
+	}
+	if moniker.Identifier == "" {
+		ctx.AddError("moniker has an empty identifier").AddContext(lineContext)
+		return false
+	}
+	if moniker.Kind != "import" && moniker.Kind != "export" && moniker.Kind != "local" {
+		ctx.AddError("illegal moniker kind %q", moniker.Kind).AddContext(lineContext)
+		return false
+	}
+
+	return true
+}
+
+// validatePackageInformationVertex ensures that the given packageInformation vertex has a name
+// and a package manager.
+func validatePackageInformationVertex(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	packageInformation, ok := lineContext.Element.Payload.(reader2.PackageInformation)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	if packageInformation.Name == "" {
+		ctx.AddError("package information has an empty name").AddContext(lineContext)
+		return false
+	}
+	if packageInformation.Manager == "" {
+		ctx.AddError("package information has an empty manager").AddContext(lineContext)
+		return false
+	}
+
+	return true
+}
+
+// validateHoverResultVertex ensures that the contents of the given hoverResult vertex are a
+// MarkedString, an array of MarkedStrings, or a MarkupContent of a known kind, and that the range
+// of the hover, if any, has valid bounds and extents. Empty arrays are reported by
+// validateNonEmptyHover.
+func validateHoverResultVertex(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	hover, ok := lineContext.Element.Payload.(reader2.HoverResult)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	for _, part := range hover.Parts {
+		switch part.Shape {
+		case reader2.HoverPartNull:
+			ctx.AddError("hover contents contains null").AddContext(lineContext)
+			return false
+
+		case reader2.HoverPartMarkupContent:
+			if hover.IsArray {
+				ctx.AddError("hover contents array contains a MarkupContent").AddContext(lineContext)
+				return false
+			}
+			if part.Kind != "plaintext" && part.Kind != "markdown" {
+				ctx.AddError("illegal markup kind %q", part.Kind).AddContext(lineContext)
+				return false
+			}
+		}
+	}
+
+	if hover.Range != nil {
+		if message := checkRangeBounds(*hover.Range); message != "" {
+			ctx.AddError("%s of hover", message).AddContext(lineContext)
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateNonEmptyHover ensures that the contents of the given hoverResult vertex contain text
+// other than whitespace. An empty array of MarkedStrings is an empty hover.
+func validateNonEmptyHover(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	hover, ok := lineContext.Element.Payload.(reader2.HoverResult)
+	if !ok {
+		return true
+	}
+
+	for _, part := range hover.Parts {
+		if !part.Blank {
+			return true
+		}
+	}
+
+	ctx.AddError("hover is empty").AddContext(lineContext)
+	return false
+}
+
+// validateDiagnosticResultVertex ensures that each diagnostic of the given diagnosticResult vertex
+// has a range with valid bounds and extents and, if it has a severity, a legal severity.
+func validateDiagnosticResultVertex(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	diagnostics, ok := lineContext.Element.Payload.([]reader.Diagnostic)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	for i, diagnostic := range diagnostics {
+		r := reader.Range{
+			StartLine:      diagnostic.StartLine,
+			StartCharacter: diagnostic.StartCharacter,
+			EndLine:        diagnostic.EndLine,
+			EndCharacter:   diagnostic.EndCharacter,
+		}
+		if message := checkRangeBounds(r); message != "" {
+			ctx.AddError("%s of diagnostic %d", message, i).AddContext(lineContext)
+			return false
+		}
+
+		if diagnostic.Severity < 0 || diagnostic.Severity > 4 {
+			ctx.AddError("illegal severity %d of diagnostic %d", diagnostic.Severity, i).AddContext(lineContext)
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateEventVertex ensures that the given $event vertex has a legal kind and scope, and that
+// its data property refers to a previously defined vertex of the type named by its scope.
+func validateEventVertex(ctx *ValidationContext, lineContext reader2.LineContext) bool {
+	event, ok := lineContext.Element.Payload.(reader2.Event)
+	if !ok {
+		ctx.AddError("illegal payload").AddContext(lineContext)
+		return false
+	}
+
+	if event.Kind != "begin" && event.Kind != "end" {
+		ctx.AddError("illegal event kind %q", event.Kind).AddContext(lineContext)
+		return false
+	}
+	if event.Scope != "document" && event.Scope != "project" {
+		ctx.AddError("illegal event scope %q", event.Scope).AddContext(lineContext)
+		return false
+	}
+
+	label, ok := ctx.Stasher.VertexLabel(event.Data)
+	if !ok {
+		ctx.AddError("no such vertex %d", event.Data).AddContext(lineContext)
+		return false
+	}
+	if label != event.Scope {
+		ctx.AddError("expected vertex %d to be of type %s", event.Data, event.Scope).AddContext(lineContext)
+		return false
+	}
+
+	return true
+}